@@ -0,0 +1,102 @@
+package golang
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/sqlc-dev/sqlc-gen-go/internal/opts"
+)
+
+// annotationPrefix marks a query comment line as an annotation, e.g.
+//
+//	-- @sqlc-gen-go:result-pointer
+const annotationPrefix = "@sqlc-gen-go:"
+
+type queryAnnotations struct {
+	Options      *opts.Options // Options with the per-query overrides applied
+	Comments     []string      // Query comments with the annotations removed
+	ParamsStruct string        // Name of the params struct, if one was requested
+	ResultStruct string        // Name of the result struct, if one was requested
+	ModelParams  bool          // Whether the params must be passed as a table model
+	Nest         string        // Embedded table to aggregate into a slice
+	NullEmbeds   []string      // Embedded tables that may be missing from a row
+	Deprecated   string        // Deprecation message, if the query is deprecated
+}
+
+// parseQueryAnnotations extracts the annotations from a query's comments and
+// applies them on top of a copy of the options.
+func parseQueryAnnotations(name string, comments []string, options *opts.Options) (*queryAnnotations, error) {
+	qopts := *options
+	a := queryAnnotations{Options: &qopts}
+	for _, line := range comments {
+		text := strings.TrimSpace(line)
+		if !strings.HasPrefix(text, annotationPrefix) {
+			a.Comments = append(a.Comments, line)
+			continue
+		}
+		key, value, hasValue := strings.Cut(strings.TrimPrefix(text, annotationPrefix), "=")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if hasValue && value == "" {
+			return nil, fmt.Errorf("query %s: annotation %s%s: missing value", name, annotationPrefix, key)
+		}
+		switch key {
+		case "result-pointer":
+			qopts.EmitResultStructPointers = true
+		case "params-pointer":
+			qopts.EmitParamsStructPointers = true
+		case "emit-empty-slice":
+			qopts.EmitEmptySlices = true
+		case "params-struct":
+			a.ParamsStruct = name + "Params"
+			if hasValue {
+				if !token.IsIdentifier(value) || !token.IsExported(value) {
+					return nil, fmt.Errorf("query %s: annotation %sparams-struct: %q is not an exported Go identifier", name, annotationPrefix, value)
+				}
+				a.ParamsStruct = value
+			}
+			continue
+		case "model-params":
+			a.ModelParams = true
+		case "result-struct":
+			if !hasValue {
+				return nil, fmt.Errorf("query %s: annotation %sresult-struct: missing value", name, annotationPrefix)
+			}
+			if !token.IsIdentifier(value) || !token.IsExported(value) {
+				return nil, fmt.Errorf("query %s: annotation %sresult-struct: %q is not an exported Go identifier", name, annotationPrefix, value)
+			}
+			a.ResultStruct = value
+			continue
+		case "nest":
+			if !hasValue {
+				return nil, fmt.Errorf("query %s: annotation %snest: missing value", name, annotationPrefix)
+			}
+			a.Nest = value
+			continue
+		case "nullable-embed":
+			if !hasValue {
+				return nil, fmt.Errorf("query %s: annotation %snullable-embed: missing value", name, annotationPrefix)
+			}
+			for _, table := range strings.Split(value, ",") {
+				a.NullEmbeds = append(a.NullEmbeds, strings.TrimSpace(table))
+			}
+			continue
+		case "deprecated":
+			a.Deprecated = "do not use in new code."
+			if hasValue {
+				a.Deprecated = value
+			}
+			continue
+		default:
+			return nil, fmt.Errorf("query %s: unknown annotation %s%s", name, annotationPrefix, key)
+		}
+		if hasValue {
+			return nil, fmt.Errorf("query %s: annotation %s%s does not take a value", name, annotationPrefix, key)
+		}
+	}
+	if a.ModelParams && a.ParamsStruct != "" {
+		return nil, fmt.Errorf("query %s: annotations %smodel-params and %sparams-struct are mutually exclusive", name, annotationPrefix, annotationPrefix)
+	}
+	return &a, nil
+}
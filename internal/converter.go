@@ -0,0 +1,156 @@
+package golang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"github.com/sqlc-dev/sqlc-gen-go/internal/opts"
+)
+
+// A Converter binds and scans a field through a value of its native type,
+// the type the column has without overrides, for Go types the driver can't
+// handle. See the encode and decode override options.
+type Converter struct {
+	Encode string // func(GoType) Native, empty to bind the field as is
+	Decode string // func(Native) (GoType, error), empty to scan the field as is
+	Native string
+
+	encodeImport ImportSpec
+	decodeImport ImportSpec
+
+	// elementwise is set when the override applies to each element of an
+	// array column, which converters don't support.
+	elementwise bool
+}
+
+// goTypeConverter returns the converter of the override setting the type of
+// a column, if the override has one.
+func goTypeConverter(req *plugin.GenerateRequest, options *opts.Options, ids *typedIDIndex, qo queryOverrideIndex, col *plugin.Column) *Converter {
+	override := columnOverride(req, options, qo, col)
+	elementwise := false
+	if override == nil {
+		if typedIDType(req, options, ids, col) != "" {
+			return nil
+		}
+		override = dbTypeOverride(options, col)
+		elementwise = col.IsArray
+	}
+	if override == nil || (override.GoEncode == nil && override.GoDecode == nil) {
+		return nil
+	}
+	native := *options
+	native.Overrides = nil
+	c := &Converter{
+		Native:      goType(req, &native, ids, nil, col),
+		elementwise: elementwise,
+	}
+	if f := override.GoEncode; f != nil {
+		c.Encode = f.TypeName
+		c.encodeImport = ImportSpec{Path: f.ImportPath, ID: f.Package}
+	}
+	if f := override.GoDecode; f != nil {
+		c.Decode = f.TypeName
+		c.decodeImport = ImportSpec{Path: f.ImportPath, ID: f.Package}
+	}
+	return c
+}
+
+// Bind returns the expression binding a field's value as a query parameter.
+func (gf Field) Bind(expr string) string {
+	if gf.Converter == nil || gf.Converter.Encode == "" {
+		return expr
+	}
+	return gf.Converter.Encode + "(" + expr + ")"
+}
+
+// HasEncoder reports whether the field is bound through a converter.
+func (gf Field) HasEncoder() bool {
+	return gf.Converter != nil && gf.Converter.Encode != ""
+}
+
+// hasDecoder reports whether the field is scanned through a converter.
+func (gf Field) hasDecoder() bool {
+	return gf.Converter != nil && gf.Converter.Decode != ""
+}
+
+// nativeVar returns the variable a field with a decoder is scanned into.
+// Fields of embedded tables are prefixed with the name of their embed.
+func (gf Field) nativeVar(embed *Field) string {
+	// Lowercase a leading initialism as a whole, e.g. ID becomes id and
+	// URLPath becomes urlPath.
+	n := 0
+	for n < len(gf.Name) && 'A' <= gf.Name[n] && gf.Name[n] <= 'Z' {
+		n++
+	}
+	if n > 1 && n < len(gf.Name) {
+		n--
+	}
+	name := strings.ToLower(gf.Name[:n]) + gf.Name[n:]
+	if embed != nil {
+		return name + embed.Name + "Native"
+	}
+	return name + "Native"
+}
+
+// decodeStmt returns the statement decoding the native value scanned for a
+// field into target. Without onError, the statement only runs when err is
+// nil and leaves a failed conversion in err.
+func decodeStmt(target string, c *Converter, native, onError string) string {
+	assign := target + ", err = " + c.Decode + "(" + native + ")"
+	if onError == "" {
+		return "if err == nil {\n" + assign + "\n}"
+	}
+	return assign + "\nif err != nil {\n" + onError + "\n}"
+}
+
+// checkConverters reports converters the generated code can't use.
+func checkConverters(v QueryValue) error {
+	if v.Converter != nil && v.Converter.elementwise {
+		return fmt.Errorf("%s: %s", v.Name, errElementwise)
+	}
+	if v.Struct == nil {
+		return nil
+	}
+	for _, f := range v.Struct.Fields {
+		if f.Converter != nil && f.Converter.elementwise {
+			return fmt.Errorf("%s: %s", f.Name, errElementwise)
+		}
+		for _, embed := range f.EmbedFields {
+			if embed.Converter != nil && embed.Converter.elementwise {
+				return fmt.Errorf("%s.%s: %s", f.Name, embed.Name, errElementwise)
+			}
+			if f.EmbedNullable && embed.hasDecoder() {
+				return fmt.Errorf("%s.%s: decode converters can't be used in nullable embedded tables", f.Name, embed.Name)
+			}
+		}
+	}
+	return nil
+}
+
+const errElementwise = "encode and decode converters can't be used with a db_type override of an array column, override the column instead"
+
+// converterImports adds the packages of the encoders used to bind the
+// params of the queries and the decoders used to scan their results.
+func converterImports(queries []Query, std map[string]struct{}, pkg map[ImportSpec]struct{}) {
+	add := func(spec ImportSpec) {
+		if spec.Path != "" {
+			addImport(std, pkg, spec.Path, spec.ID)
+		}
+	}
+	for _, q := range queries {
+		for _, c := range q.Arg.converters() {
+			if c.Encode != "" {
+				add(c.encodeImport)
+			}
+		}
+		if !q.hasRetType() {
+			continue
+		}
+		for _, c := range q.Ret.converters() {
+			if c.Decode != "" {
+				add(c.decodeImport)
+			}
+		}
+	}
+}
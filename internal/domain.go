@@ -0,0 +1,45 @@
+package golang
+
+import (
+	"go/types"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"github.com/sqlc-dev/sqlc-gen-go/internal/opts"
+)
+
+type Domain struct {
+	Name string
+	Type string
+}
+
+func domainTypeName(req *plugin.GenerateRequest, options *opts.Options, d opts.Domain) string {
+	if d.DomainSchema == req.Catalog.DefaultSchema {
+		return StructName(d.DomainName, options)
+	}
+	return StructName(d.DomainSchema+"_"+d.DomainName, options)
+}
+
+// domainBaseColumn returns a copy of col with its type replaced by the
+// underlying type of the domain.
+func domainBaseColumn(col *plugin.Column, d opts.Domain, notNull bool) *plugin.Column {
+	return &plugin.Column{
+		Name:     col.Name,
+		NotNull:  notNull,
+		IsArray:  col.IsArray,
+		Unsigned: col.Unsigned,
+		Table:    col.Table,
+		Type:     d.BaseType(),
+	}
+}
+
+// A named Go type is only emitted for domains over basic types, as a
+// defined type does not inherit the Scan and Value methods of its underlying
+// type.
+func isBasicGoType(typ string) bool {
+	obj := types.Universe.Lookup(typ)
+	if obj == nil {
+		return false
+	}
+	_, ok := obj.Type().(*types.Basic)
+	return ok
+}
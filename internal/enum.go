@@ -3,6 +3,9 @@ package golang
 import (
 	"strings"
 	"unicode"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"github.com/sqlc-dev/sqlc-gen-go/internal/opts"
 )
 
 type Constant struct {
@@ -13,8 +16,11 @@ type Constant struct {
 
 type Enum struct {
 	Name      string
+	DBName    string // Name as used in the DB, qualified outside the default schema
 	Comment   string
 	Constants []Constant
+	ArrayName string // Set when a generated array type is required, see goEnumArrayType
+	SetName   string // Set for the enums of MySQL SET columns, see mysql_set_columns
 	NameTags  map[string]string
 	ValidTags map[string]string
 }
@@ -27,6 +33,51 @@ func (e Enum) ValidTag() string {
 	return TagsToString(e.ValidTags)
 }
 
+// enumOverride returns the db_type override replacing an enum, if any. The
+// enum is then not generated, and its columns use the Go type of the
+// override instead. As its Null type is gone too, nullable columns use a
+// pointer to that type, unless a `nullable: true` override sets their type.
+func enumOverride(options *opts.Options, dbNames ...string) *opts.Override {
+	for i := range options.Overrides {
+		override := &options.Overrides[i]
+		if override.GoTypeName == "" || override.Nullable || override.GoElement {
+			continue
+		}
+		for _, name := range dbNames {
+			if override.DBType == name {
+				return override
+			}
+		}
+	}
+	return nil
+}
+
+// isEnumColumn reports whether the type of a column is an enum.
+func isEnumColumn(req *plugin.GenerateRequest, col *plugin.Column) bool {
+	for _, schema := range req.Catalog.Schemas {
+		if col.Type.Schema != "" && col.Type.Schema != schema.Name {
+			continue
+		}
+		for _, enum := range schema.Enums {
+			if enum.Name == col.Type.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func enumConstantName(enumName, value string, options *opts.Options) string {
+	switch options.EnumNaming {
+	case opts.EnumNamingSuffix:
+		return StructName(value+"_"+enumName, options)
+	case opts.EnumNamingValue:
+		return StructName(value, options)
+	default:
+		return StructName(enumName+"_"+value, options)
+	}
+}
+
 func enumReplacer(r rune) rune {
 	if strings.ContainsRune("-/:_", r) {
 		return '_'
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/sqlc-dev/sqlc-gen-go/internal/opts"
+	"github.com/sqlc-dev/plugin-sdk-go/sdk"
 	"github.com/sqlc-dev/plugin-sdk-go/plugin"
 )
 
@@ -17,14 +18,99 @@ type Field struct {
 	Tags    map[string]string
 	Comment string
 	Column  *plugin.Column
+	// ArrayType is the generated type used to scan and bind the field when
+	// the driver can't handle its slice type on its own.
+	ArrayType string
 	// EmbedFields contains the embedded fields that require scanning.
 	EmbedFields []Field
+	// Nested is set when the embedded table is aggregated into a slice of
+	// its model, see nestEmbed.
+	Nested bool
+	// EmbedNullable is set when the embedded table may be missing from a
+	// row, see nullableEmbeds.
+	EmbedNullable bool
+	// OptionalOf is set on the boolean set_x parameter of an Optional field
+	// named x. The flag is bound from the Optional instead of being a field
+	// of its own, see optionalParams.
+	OptionalOf string
+	// Converter is set when the field is bound and scanned through the
+	// encode and decode functions of an override.
+	Converter *Converter
 }
 
 func (gf Field) Tag() string {
 	return TagsToString(gf.Tags)
 }
 
+// DBType returns the database type of the field's column.
+func (gf Field) DBType() string {
+	if gf.Column == nil || gf.Column.Type == nil {
+		return ""
+	}
+	typ := sdk.DataType(gf.Column.Type)
+	if gf.Column.IsArray {
+		typ += strings.Repeat("[]", max(int(gf.Column.ArrayDims), 1))
+	}
+	return typ
+}
+
+// ElemType returns the element type of a nested field.
+func (gf Field) ElemType() string {
+	return strings.TrimPrefix(gf.Type, "[]")
+}
+
+// scanVar returns the variable that a nested or nullable embedded table is
+// scanned into before it is assigned to the result.
+func (gf Field) scanVar() string {
+	if gf.Nested {
+		return "child"
+	}
+	return sdk.LowerTitle(gf.Name) + "Null"
+}
+
+// scannedAsPointer reports whether an embedded field is scanned through a
+// pointer, so that a missing row can be told apart from a present one.
+// Nullable columns and slices already accept NULL.
+func (gf Field) scannedAsPointer() bool {
+	return gf.Column != nil && gf.Column.NotNull && !strings.HasPrefix(gf.Type, "[]")
+}
+
+// embedPresent returns the condition under which a nullable embedded table
+// scanned into its scanVar holds a row.
+func (gf Field) embedPresent() string {
+	var conds []string
+	for _, embed := range gf.EmbedFields {
+		if embed.scannedAsPointer() {
+			conds = append(conds, gf.scanVar()+"."+embed.Name+" != nil")
+		}
+	}
+	return strings.Join(conds, " && ")
+}
+
+// embedValue returns the model value of an embedded table scanned into its
+// scanVar.
+func (gf Field) embedValue() string {
+	if !gf.EmbedNullable {
+		return gf.scanVar()
+	}
+	model := strings.TrimPrefix(gf.ElemType(), "*")
+	return model + gf.embedFieldValues()
+}
+
+// embedFieldValues returns the braced field values of the model literal
+// built by embedValue, without the model type.
+func (gf Field) embedFieldValues() string {
+	var out []string
+	for _, embed := range gf.EmbedFields {
+		value := gf.scanVar() + "." + embed.Name
+		if embed.scannedAsPointer() {
+			value = "*" + value
+		}
+		out = append(out, embed.Name+": "+value+",")
+	}
+	return "{\n" + strings.Join(out, "\n") + "\n}"
+}
+
 func (gf Field) HasSqlcSlice() bool {
 	return gf.Column.IsSqlcSlice
 }
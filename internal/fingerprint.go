@@ -0,0 +1,127 @@
+package golang
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// queryFingerprint returns a stable identifier for a query. It is computed
+// from the normalized query text, so changes to comments, whitespace or the
+// case of keywords don't change the fingerprint.
+func queryFingerprint(sql string) string {
+	sum := sha256.Sum256([]byte(normalizeSQL(sql)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// normalizeSQL removes comments, collapses whitespace and lowercases
+// everything outside of quoted strings, quoted identifiers and dollar-quoted
+// strings.
+func normalizeSQL(sql string) string {
+	var b strings.Builder
+	space := false
+	write := func(s string) {
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteString(s)
+	}
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			i += end
+			space = true
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			// PostgreSQL allows block comments to nest.
+			depth := 0
+			for i < len(sql) {
+				if strings.HasPrefix(sql[i:], "/*") {
+					depth++
+					i += 2
+				} else if strings.HasPrefix(sql[i:], "*/") {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+			space = true
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(sql) {
+				if sql[end] == c {
+					if end+1 < len(sql) && sql[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end+1, len(sql))
+			write(sql[i:end])
+			i = end
+		case c == '$' && dollarQuoteTag(sql[i:]) != "" && (i == 0 || !isIdentByte(sql[i-1])):
+			tag := dollarQuoteTag(sql[i:])
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				end = len(sql)
+			} else {
+				end += i + 2*len(tag)
+			}
+			write(sql[i:end])
+			i = end
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			space = true
+			i++
+		default:
+			if 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			write(string([]byte{c}))
+			i++
+		}
+	}
+	return strings.TrimSpace(strings.TrimSuffix(b.String(), ";"))
+}
+
+// dollarQuoteTag returns the opening delimiter of the dollar-quoted string
+// sql starts with, e.g. $$ or $body$, or an empty string.
+func dollarQuoteTag(sql string) string {
+	for i := 1; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '$':
+			return sql[:i+1]
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80:
+		case '0' <= c && c <= '9' && i > 1:
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c >= 0x80
+}
+
+// sqlComment returns a sqlcommenter formatted comment holding the given
+// key/value pairs, which must already be sorted by key.
+func sqlComment(pairs ...[2]string) string {
+	tags := make([]string, len(pairs))
+	for i, p := range pairs {
+		tags[i] = p[0] + "='" + url.PathEscape(p[1]) + "'"
+	}
+	return "/*" + strings.Join(tags, ",") + "*/"
+}
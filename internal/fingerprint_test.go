@@ -0,0 +1,45 @@
+package golang
+
+import "testing"
+
+func TestNormalizeSQL(t *testing.T) {
+	for _, tt := range []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT  *\n\tFROM authors;", "select * from authors"},
+		{"SELECT * FROM authors -- all of them\nWHERE id = $1", "select * from authors where id = $1"},
+		{"SELECT /* outer /* nested */ comment */ 1", "select 1"},
+		{"SELECT 'Don''t -- Touch' FROM t", "select 'Don''t -- Touch' from t"},
+		{`SELECT "UserName", "a""b" FROM "Users"`, `select "UserName", "a""b" from "Users"`},
+		{"SELECT $$Keep  THIS$$, $body$It's /* here */$body$", "select $$Keep  THIS$$, $body$It's /* here */$body$"},
+		{"SELECT $1, $2 FROM t WHERE a$b = 1", "select $1, $2 from t where a$b = 1"},
+		{"SELECT `Name` FROM `Authors`", "select `Name` from `Authors`"},
+	} {
+		if got := normalizeSQL(tt.sql); got != tt.want {
+			t.Errorf("normalizeSQL(%q)\n got: %q\nwant: %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestQueryFingerprint(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		same bool
+	}{
+		{"SELECT * FROM authors", "select *\n  from authors;", true},
+		{"SELECT * FROM authors", "-- name: ListAuthors :many\nSELECT * /* all */ FROM authors", true},
+		{"SELECT * FROM authors WHERE name = 'Ann'", "SELECT * FROM authors WHERE name = 'ann'", false},
+		{`SELECT "UserName" FROM users`, `SELECT "username" FROM users`, false},
+		{"SELECT $$Ann$$", "SELECT $$ann$$", false},
+		{"SELECT $tag$Ann$tag$", "SELECT $tag$ANN$tag$", false},
+		{"SELECT * FROM authors", "SELECT * FROM books", false},
+	} {
+		if got := queryFingerprint(tt.a) == queryFingerprint(tt.b); got != tt.same {
+			t.Errorf("queryFingerprint(%q) == queryFingerprint(%q) is %v", tt.a, tt.b, got)
+		}
+	}
+	if got := queryFingerprint("SELECT 1"); len(got) != 16 {
+		t.Errorf("queryFingerprint returned %q, want 16 hex digits", got)
+	}
+}
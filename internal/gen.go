@@ -21,6 +21,8 @@ type tmplCtx struct {
 	Package     string
 	SQLDriver   opts.SQLDriver
 	Enums       []Enum
+	Domains     []Domain
+	TypedIDs    []TypedID
 	Structs     []Struct
 	GoQueries   []Query
 	SqlcVersion string
@@ -33,11 +35,18 @@ type tmplCtx struct {
 	EmitDBTags                bool
 	EmitPreparedQueries       bool
 	EmitInterface             bool
-	EmitEmptySlices           bool
 	EmitMethodsWithDBArgument bool
 	EmitEnumValidMethod       bool
 	EmitAllEnumValues         bool
+	EmitStrictEnums           bool
+	EmitModelDescriptors      bool
+	EmitRegisterTypes         bool
+	EmitQueryRegistry         bool
+	EmitQueryFingerprints     bool
+	PgxTypeNames              []string
 	UsesCopyFrom              bool
+	UsesEnumArrays            bool
+	UsesOptional              bool
 	UsesBatch                 bool
 	OmitSqlcVersion           bool
 	BuildTags                 string
@@ -115,36 +124,132 @@ func Generate(ctx context.Context, req *plugin.GenerateRequest) (*plugin.Generat
 	}
 
 	enums := buildEnums(req, options)
-	structs := buildStructs(req, options)
-	queries, err := buildQueries(req, options, structs)
+	domains := buildDomains(req, options)
+	typedIDs, ids, err := buildTypedIDs(req, options)
+	if err != nil {
+		return nil, err
+	}
+	qo := buildQueryOverrides(req, options)
+	structs := buildStructs(req, options, ids)
+	queries, err := buildQueries(req, options, ids, qo, structs)
 	if err != nil {
 		return nil, err
 	}
 
 	if options.OmitUnusedStructs {
-		enums, structs = filterUnusedStructs(enums, structs, queries)
+		enums, domains, typedIDs, structs = filterUnusedStructs(enums, domains, typedIDs, structs, queries)
+	}
+	if options.StrictTypes {
+		if err := checkStrictTypes(req, options, qo, structs, queries); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkPQArrays(req, options, qo, structs, queries); err != nil {
+		return nil, err
+	}
+	markEnumArrays(enums, structs, queries)
+	if options.EmitModelConversions {
+		addModelConversions(req, structs, queries)
 	}
 
-	if err := validate(options, enums, structs, queries); err != nil {
+	if err := validate(options, enums, domains, typedIDs, structs, queries); err != nil {
 		return nil, err
 	}
 
-	return generate(req, options, enums, structs, queries)
+	return generate(req, options, enums, domains, typedIDs, structs, queries)
 }
 
-func validate(options *opts.Options, enums []Enum, structs []Struct, queries []Query) error {
+func validate(options *opts.Options, enums []Enum, domains []Domain, typedIDs []TypedID, structs []Struct, queries []Query) error {
 	enumNames := make(map[string]struct{})
 	for _, enum := range enums {
 		enumNames[enum.Name] = struct{}{}
 		enumNames["Null"+enum.Name] = struct{}{}
+		if enum.ArrayName != "" {
+			enumNames[enum.ArrayName] = struct{}{}
+		}
+		if enum.SetName != "" {
+			enumNames[enum.SetName] = struct{}{}
+		}
+	}
+	domainNames := make(map[string]struct{})
+	for _, domain := range domains {
+		if _, ok := enumNames[domain.Name]; ok {
+			return fmt.Errorf("domain name conflicts with enum name: %s", domain.Name)
+		}
+		domainNames[domain.Name] = struct{}{}
+	}
+	typedIDNames := make(map[string]struct{})
+	for _, id := range typedIDs {
+		if _, ok := enumNames[id.Name]; ok {
+			return fmt.Errorf("typed ID name of %s conflicts with enum name: %s", id.DBName, id.Name)
+		}
+		if _, ok := domainNames[id.Name]; ok {
+			return fmt.Errorf("typed ID name of %s conflicts with domain name: %s", id.DBName, id.Name)
+		}
+		typedIDNames[id.Name] = struct{}{}
+	}
+	constants := make(map[string]Constant)
+	for _, enum := range enums {
+		for _, c := range enum.Constants {
+			if c.Name == "" {
+				return fmt.Errorf("enum %s: value %q does not produce a Go identifier, set a name with enum_value_rename", enum.Name, c.Value)
+			}
+			if other, ok := constants[c.Name]; ok {
+				return fmt.Errorf("enum constant name %s is used by both %s value %q and %s value %q, set a different name with enum_value_rename", c.Name, other.Type, other.Value, c.Type, c.Value)
+			}
+			if _, ok := enumNames[c.Name]; ok {
+				return fmt.Errorf("enum constant name conflicts with enum name: %s", c.Name)
+			}
+			constants[c.Name] = c
+		}
+	}
+	if options.EmitStrictEnums && len(enums) > 0 {
+		enumNames["InvalidEnumValueError"] = struct{}{}
+	}
+	if options.EmitModelDescriptors && len(structs) > 0 {
+		enumNames["ColumnDescriptor"] = struct{}{}
+	}
+	if options.EmitOptionalParams {
+		enumNames["Optional"] = struct{}{}
+	}
+	if options.EmitQueryRegistry {
+		enumNames["QueryDescriptor"] = struct{}{}
+		enumNames["ParamDescriptor"] = struct{}{}
+		enumNames["QueryDescriptors"] = struct{}{}
 	}
 	structNames := make(map[string]struct{})
 	for _, struckt := range structs {
 		if _, ok := enumNames[struckt.Name]; ok {
 			return fmt.Errorf("struct name conflicts with enum name: %s", struckt.Name)
 		}
+		if _, ok := domainNames[struckt.Name]; ok {
+			return fmt.Errorf("struct name conflicts with domain name: %s", struckt.Name)
+		}
+		if _, ok := typedIDNames[struckt.Name]; ok {
+			return fmt.Errorf("struct name conflicts with typed ID name: %s", struckt.Name)
+		}
+		if _, ok := constants[struckt.Name]; ok {
+			return fmt.Errorf("struct name conflicts with enum constant name: %s", struckt.Name)
+		}
+		if options.EmitModelDescriptors {
+			for _, f := range struckt.Fields {
+				if f.Name == "TableName" || f.Name == "ColumnDescriptors" {
+					return fmt.Errorf("struct %s field name conflicts with model descriptor method: %s", struckt.Name, f.Name)
+				}
+			}
+		}
 		structNames[struckt.Name] = struct{}{}
 	}
+	descriptorNames := make(map[string]struct{})
+	if options.EmitModelDescriptors {
+		for _, struckt := range structs {
+			name := struckt.Name + "Columns"
+			if _, ok := structNames[name]; ok {
+				return fmt.Errorf("struct name conflicts with model descriptor name: %s", name)
+			}
+			descriptorNames[name] = struct{}{}
+		}
+	}
 	if !options.EmitExportedQueries {
 		return nil
 	}
@@ -155,16 +260,26 @@ func validate(options *opts.Options, enums []Enum, structs []Struct, queries []Q
 		if _, ok := structNames[query.ConstantName]; ok {
 			return fmt.Errorf("query constant name conflicts with struct name: %s", query.ConstantName)
 		}
+		if _, ok := descriptorNames[query.ConstantName]; ok {
+			return fmt.Errorf("query constant name conflicts with model descriptor name: %s", query.ConstantName)
+		}
+		if options.EmitQueryFingerprints {
+			name := query.ConstantName + "Fingerprint"
+			if _, ok := structNames[name]; ok {
+				return fmt.Errorf("query fingerprint constant name conflicts with struct name: %s", name)
+			}
+		}
 	}
 	return nil
 }
 
-func generate(req *plugin.GenerateRequest, options *opts.Options, enums []Enum, structs []Struct, queries []Query) (*plugin.GenerateResponse, error) {
+func generate(req *plugin.GenerateRequest, options *opts.Options, enums []Enum, domains []Domain, typedIDs []TypedID, structs []Struct, queries []Query) (*plugin.GenerateResponse, error) {
 	i := &importer{
-		Options: options,
-		Queries: queries,
-		Enums:   enums,
-		Structs: structs,
+		Options:  options,
+		Queries:  queries,
+		Enums:    enums,
+		TypedIDs: typedIDs,
+		Structs:  structs,
 	}
 
 	tctx := tmplCtx{
@@ -173,16 +288,25 @@ func generate(req *plugin.GenerateRequest, options *opts.Options, enums []Enum,
 		JsonTagsIDUppercase:       options.JsonTagsIdUppercase,
 		EmitDBTags:                options.EmitDbTags,
 		EmitPreparedQueries:       options.EmitPreparedQueries,
-		EmitEmptySlices:           options.EmitEmptySlices,
 		EmitMethodsWithDBArgument: options.EmitMethodsWithDbArgument,
 		EmitEnumValidMethod:       options.EmitEnumValidMethod,
 		EmitAllEnumValues:         options.EmitAllEnumValues,
+		EmitStrictEnums:           options.EmitStrictEnums,
+		EmitModelDescriptors:      options.EmitModelDescriptors,
+		EmitRegisterTypes:         options.EmitRegisterTypes,
+		EmitQueryRegistry:         options.EmitQueryRegistry,
+		EmitQueryFingerprints:     options.EmitQueryFingerprints,
+		PgxTypeNames:              pgxTypeNames(req, options, enums, structs, queries),
 		UsesCopyFrom:              usesCopyFrom(queries),
+		UsesEnumArrays:            usesEnumArrays(enums),
+		UsesOptional:              usesOptional(queries),
 		UsesBatch:                 usesBatch(queries),
 		SQLDriver:                 parseDriver(options.SqlPackage),
 		Q:                         "`",
 		Package:                   options.Package,
 		Enums:                     enums,
+		Domains:                   domains,
+		TypedIDs:                  typedIDs,
 		Structs:                   structs,
 		SqlcVersion:               req.SqlcVersion,
 		BuildTags:                 options.BuildTags,
@@ -283,6 +407,10 @@ func generate(req *plugin.GenerateRequest, options *opts.Options, enums []Enum,
 	if options.OutputBatchFileName != "" {
 		batchFileName = options.OutputBatchFileName
 	}
+	registryFileName := "registry.go"
+	if options.OutputRegistryFileName != "" {
+		registryFileName = options.OutputRegistryFileName
+	}
 
 	if err := execute(dbFileName, "dbFile"); err != nil {
 		return nil, err
@@ -305,6 +433,11 @@ func generate(req *plugin.GenerateRequest, options *opts.Options, enums []Enum,
 			return nil, err
 		}
 	}
+	if options.EmitQueryRegistry {
+		if err := execute(registryFileName, "registryFile"); err != nil {
+			return nil, err
+		}
+	}
 
 	files := map[string]struct{}{}
 	for _, gq := range queries {
@@ -337,6 +470,74 @@ func usesCopyFrom(queries []Query) bool {
 	return false
 }
 
+func usesEnumArrays(enums []Enum) bool {
+	for _, enum := range enums {
+		if enum.ArrayName != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func usesEnumSets(enums []Enum) bool {
+	for _, enum := range enums {
+		if enum.SetName != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// markEnumArrays sets the ArrayName of every enum whose array type is
+// referenced by a generated struct or query.
+func markEnumArrays(enums []Enum, structs []Struct, queries []Query) {
+	used := make(map[string]struct{})
+	addFields := func(fields []Field) {
+		for _, f := range fields {
+			if f.ArrayType != "" {
+				used[f.ArrayType] = struct{}{}
+			}
+			for _, embed := range f.EmbedFields {
+				if embed.ArrayType != "" {
+					used[embed.ArrayType] = struct{}{}
+				}
+			}
+		}
+	}
+	for _, s := range structs {
+		addFields(s.Fields)
+	}
+	for _, q := range queries {
+		for _, v := range []QueryValue{q.Arg, q.Ret} {
+			if v.ArrayType != "" {
+				used[v.ArrayType] = struct{}{}
+			}
+			if v.Struct != nil {
+				addFields(v.Struct.Fields)
+			}
+		}
+	}
+	for i := range enums {
+		if _, ok := used[enums[i].Name+"Array"]; ok {
+			enums[i].ArrayName = enums[i].Name + "Array"
+		}
+	}
+}
+
+func usesOptional(queries []Query) bool {
+	for _, q := range queries {
+		if q.Arg.Struct == nil {
+			continue
+		}
+		for _, f := range q.Arg.Struct.Fields {
+			if f.OptionalOf != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func usesBatch(queries []Query) bool {
 	for _, q := range queries {
 		for _, cmd := range []string{metadata.CmdBatchExec, metadata.CmdBatchMany, metadata.CmdBatchOne} {
@@ -348,6 +549,136 @@ func usesBatch(queries []Query) bool {
 	return false
 }
 
+// pgxTypeNames lists the enum, domain and composite types used by the
+// generated structs and queries that must be loaded into a pgx connection's
+// type map. The base type of a domain is listed before the domain, as pgx
+// can only load a type whose dependencies are registered. Composite types
+// are only listed when an override maps them to a Go type: they are
+// otherwise generated as strings, which pgx can't decode a registered
+// composite into.
+func pgxTypeNames(req *plugin.GenerateRequest, options *opts.Options, enums []Enum, structs []Struct, queries []Query) []string {
+	if !options.EmitRegisterTypes {
+		return nil
+	}
+
+	// Whether the types of the columns are used as arrays, keyed by their
+	// qualified database name
+	used := make(map[string]bool)
+	var keys []string
+	addColumn := func(col *plugin.Column, typ string) {
+		if col == nil || col.Type == nil {
+			return
+		}
+		rel, err := parseIdentifierString(sdk.DataType(col.Type))
+		if err != nil {
+			return
+		}
+		if rel.Schema == "" {
+			rel.Schema = req.Catalog.DefaultSchema
+		}
+		key := rel.Schema + "." + rel.Name
+		if isCompositeType(req, key) {
+			switch trimSliceAndPointerPrefix(typ) {
+			case "string", "sql.NullString":
+				return
+			}
+		}
+		if _, ok := used[key]; !ok {
+			keys = append(keys, key)
+		}
+		used[key] = used[key] || col.IsArray
+	}
+	addFields := func(fields []Field) {
+		for _, f := range fields {
+			addColumn(f.Column, f.Type)
+			for _, embed := range f.EmbedFields {
+				addColumn(embed.Column, embed.Type)
+			}
+		}
+	}
+	for _, s := range structs {
+		addFields(s.Fields)
+	}
+	for _, q := range queries {
+		for _, v := range []QueryValue{q.Arg, q.Ret} {
+			if v.Struct != nil {
+				addFields(v.Struct.Fields)
+			} else {
+				addColumn(v.Column, v.Typ)
+			}
+		}
+	}
+
+	enumKeys := make(map[string]struct{}, len(enums))
+	for _, enum := range enums {
+		key := enum.DBName
+		if !strings.Contains(key, ".") {
+			key = req.Catalog.DefaultSchema + "." + key
+		}
+		enumKeys[key] = struct{}{}
+	}
+	domains := make(map[string]opts.Domain, len(options.Domains))
+	for _, d := range options.Domains {
+		domains[d.DomainSchema+"."+d.DomainName] = d
+	}
+	typeName := func(key string) string {
+		schema, name, _ := strings.Cut(key, ".")
+		if schema == req.Catalog.DefaultSchema {
+			return name
+		}
+		return key
+	}
+	arrayName := func(key string) string {
+		schema, name, _ := strings.Cut(key, ".")
+		if schema == req.Catalog.DefaultSchema {
+			return "_" + name
+		}
+		return schema + "._" + name
+	}
+
+	var names []string
+	added := make(map[string]struct{})
+	var add func(key string, array bool)
+	add = func(key string, array bool) {
+		if _, ok := added[key]; ok {
+			return
+		}
+		if d, ok := domains[key]; ok {
+			// Domains never loop, see opts.validateDomains
+			base := d.BaseType()
+			if base.Schema == "" {
+				base.Schema = req.Catalog.DefaultSchema
+			}
+			add(base.Schema+"."+base.Name, false)
+		} else if _, ok := enumKeys[key]; ok {
+			// Enum arrays have always been loaded along with the enum
+			array = true
+		} else if !isCompositeType(req, key) {
+			return
+		}
+		added[key] = struct{}{}
+		names = append(names, typeName(key))
+		if array {
+			names = append(names, arrayName(key))
+		}
+	}
+	for _, key := range keys {
+		add(key, used[key])
+	}
+	return names
+}
+
+func isCompositeType(req *plugin.GenerateRequest, key string) bool {
+	for _, schema := range req.Catalog.Schemas {
+		for _, ct := range schema.CompositeTypes {
+			if schema.Name+"."+ct.Name == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func checkNoTimesForMySQLCopyFrom(queries []Query) error {
 	for _, q := range queries {
 		if q.Cmd != metadata.CmdCopyFrom {
@@ -362,7 +693,7 @@ func checkNoTimesForMySQLCopyFrom(queries []Query) error {
 	return nil
 }
 
-func filterUnusedStructs(enums []Enum, structs []Struct, queries []Query) ([]Enum, []Struct) {
+func filterUnusedStructs(enums []Enum, domains []Domain, typedIDs []TypedID, structs []Struct, queries []Query) ([]Enum, []Domain, []TypedID, []Struct) {
 	keepTypes := make(map[string]struct{})
 
 	for _, query := range queries {
@@ -370,7 +701,7 @@ func filterUnusedStructs(enums []Enum, structs []Struct, queries []Query) ([]Enu
 			keepTypes[query.Arg.Type()] = struct{}{}
 			if query.Arg.IsStruct() {
 				for _, field := range query.Arg.Struct.Fields {
-					keepTypes[field.Type] = struct{}{}
+					keepTypes[trimSliceAndPointerPrefix(field.Type)] = struct{}{}
 				}
 			}
 		}
@@ -378,9 +709,9 @@ func filterUnusedStructs(enums []Enum, structs []Struct, queries []Query) ([]Enu
 			keepTypes[query.Ret.Type()] = struct{}{}
 			if query.Ret.IsStruct() {
 				for _, field := range query.Ret.Struct.Fields {
-					keepTypes[field.Type] = struct{}{}
+					keepTypes[trimSliceAndPointerPrefix(field.Type)] = struct{}{}
 					for _, embedField := range field.EmbedFields {
-						keepTypes[embedField.Type] = struct{}{}
+						keepTypes[trimSliceAndPointerPrefix(embedField.Type)] = struct{}{}
 					}
 				}
 			}
@@ -396,6 +727,26 @@ func filterUnusedStructs(enums []Enum, structs []Struct, queries []Query) ([]Enu
 		}
 	}
 
+	keepDomains := make([]Domain, 0, len(domains))
+	for _, domain := range domains {
+		for typ := range keepTypes {
+			if trimSliceAndPointerPrefix(typ) == domain.Name {
+				keepDomains = append(keepDomains, domain)
+				break
+			}
+		}
+	}
+
+	keepTypedIDs := make([]TypedID, 0, len(typedIDs))
+	for _, id := range typedIDs {
+		for typ := range keepTypes {
+			if trimSliceAndPointerPrefix(typ) == id.Name {
+				keepTypedIDs = append(keepTypedIDs, id)
+				break
+			}
+		}
+	}
+
 	keepStructs := make([]Struct, 0, len(structs))
 	for _, st := range structs {
 		if _, ok := keepTypes[st.Name]; ok {
@@ -403,5 +754,5 @@ func filterUnusedStructs(enums []Enum, structs []Struct, queries []Query) ([]Enu
 		}
 	}
 
-	return keepEnums, keepStructs
+	return keepEnums, keepDomains, keepTypedIDs, keepStructs
 }
@@ -1,6 +1,7 @@
 package golang
 
 import (
+	"maps"
 	"strings"
 
 	"github.com/sqlc-dev/sqlc-gen-go/internal/opts"
@@ -8,10 +9,13 @@ import (
 	"github.com/sqlc-dev/plugin-sdk-go/plugin"
 )
 
-func addExtraGoStructTags(tags map[string]string, req *plugin.GenerateRequest, options *opts.Options, col *plugin.Column) {
+func addExtraGoStructTags(tags map[string]string, req *plugin.GenerateRequest, options *opts.Options, qo queryOverrideIndex, col *plugin.Column) {
+	if override, ok := qo[col]; ok {
+		maps.Copy(tags, override.ShimOverride.GoType.StructTags)
+	}
 	for _, override := range options.Overrides {
 		oride := override.ShimOverride
-		if oride.GoType.StructTags == nil {
+		if oride.GoType.StructTags == nil || override.Query != "" {
 			continue
 		}
 		if !override.Matches(col.Table, req.Catalog.DefaultSchema) {
@@ -33,12 +37,63 @@ func addExtraGoStructTags(tags map[string]string, req *plugin.GenerateRequest, o
 	}
 }
 
-func goType(req *plugin.GenerateRequest, options *opts.Options, col *plugin.Column) string {
+func goType(req *plugin.GenerateRequest, options *opts.Options, ids *typedIDIndex, qo queryOverrideIndex, col *plugin.Column) string {
 	// Check if the column's type has been overridden
-	for _, override := range options.Overrides {
+	if override := columnOverride(req, options, qo, col); override != nil {
+		if override.GoElement {
+			return arrayPrefix(col) + override.ShimOverride.GoType.TypeName
+		}
+		if col.IsSqlcSlice {
+			return "[]" + override.ShimOverride.GoType.TypeName
+		}
+		return override.ShimOverride.GoType.TypeName
+	}
+	if typ := typedIDType(req, options, ids, col); typ != "" {
+		if col.IsSqlcSlice {
+			return "[]" + typ
+		}
+		if !col.NotNull {
+			return "*" + typ
+		}
+		return typ
+	}
+	return arrayPrefix(col) + goInnerType(req, options, col)
+}
+
+// arrayPrefix returns the slice prefix of the Go type of an array column or
+// sqlc.slice parameter, one per dimension of the array.
+func arrayPrefix(col *plugin.Column) string {
+	if col.IsSqlcSlice {
+		return "[]"
+	}
+	if col.IsArray {
+		return strings.Repeat("[]", max(int(col.ArrayDims), 1))
+	}
+	return ""
+}
+
+// typeOverride returns the override setting the Go type of a column the way
+// goType resolves it, if any.
+func typeOverride(req *plugin.GenerateRequest, options *opts.Options, qo queryOverrideIndex, col *plugin.Column) *opts.Override {
+	if override := columnOverride(req, options, qo, col); override != nil {
+		return override
+	}
+	return dbTypeOverride(options, col)
+}
+
+// columnOverride returns the query scoped or column override setting the
+// type of a column, if any.
+func columnOverride(req *plugin.GenerateRequest, options *opts.Options, qo queryOverrideIndex, col *plugin.Column) *opts.Override {
+	elements := col.IsArray || col.IsSqlcSlice
+	// Query scoped overrides are the most specific
+	if override, ok := qo[col]; ok && override.ShimOverride.GoType.TypeName != "" && (elements || !override.GoElement) {
+		return override
+	}
+	for i := range options.Overrides {
+		override := &options.Overrides[i]
 		oride := override.ShimOverride
 
-		if oride.GoType.TypeName == "" {
+		if oride.GoType.TypeName == "" || (override.GoElement && !elements) {
 			continue
 		}
 		cname := col.Name
@@ -47,34 +102,77 @@ func goType(req *plugin.GenerateRequest, options *opts.Options, col *plugin.Colu
 		}
 		sameTable := override.Matches(col.Table, req.Catalog.DefaultSchema)
 		if oride.Column != "" && sdk.MatchString(oride.ColumnName, cname) && sameTable {
-			if col.IsSqlcSlice {
-				return "[]" + oride.GoType.TypeName
-			}
-			return oride.GoType.TypeName
+			return override
 		}
 	}
-	typ := goInnerType(req, options, col)
-	if col.IsSqlcSlice {
-		return "[]" + typ
-	}
-	if col.IsArray {
-		return strings.Repeat("[]", int(col.ArrayDims)) + typ
-	}
-	return typ
+	return nil
 }
 
-func goInnerType(req *plugin.GenerateRequest, options *opts.Options, col *plugin.Column) string {
+// dbTypeOverride returns the db_type override setting the type of a column,
+// if any. For array columns and sqlc.slice parameters, it is the type of
+// their elements, and go_element_type overrides win over go_type ones.
+func dbTypeOverride(options *opts.Options, col *plugin.Column) *opts.Override {
 	columnType := sdk.DataType(col.Type)
 	notNull := col.NotNull || col.IsArray
+	elements := col.IsArray || col.IsSqlcSlice
 
-	// package overrides have a higher precedence
-	for _, override := range options.Overrides {
+	var match *opts.Override
+	for i := range options.Overrides {
+		override := &options.Overrides[i]
 		oride := override.ShimOverride
-		if oride.GoType.TypeName == "" {
+		if oride.GoType.TypeName == "" || (override.GoElement && !elements) {
 			continue
 		}
 		if oride.DbType != "" && oride.DbType == columnType && oride.Nullable != notNull && oride.Unsigned == col.Unsigned {
-			return oride.GoType.TypeName
+			if override.GoElement {
+				return override
+			}
+			if match == nil {
+				match = override
+			}
+		}
+	}
+	return match
+}
+
+// goEnumArrayType returns the name of the generated array type for
+// one-dimensional arrays of enums, which lib/pq can't scan on its own. pgx
+// handles these slices once the array type is registered on the connection.
+func goEnumArrayType(req *plugin.GenerateRequest, options *opts.Options, ids *typedIDIndex, qo queryOverrideIndex, col *plugin.Column) string {
+	if col == nil || !col.IsArray || col.ArrayDims > 1 || col.IsSqlcSlice {
+		return ""
+	}
+	if req.Settings.Engine != "postgresql" || parseDriver(options.SqlPackage).IsPGX() {
+		return ""
+	}
+	elem := strings.TrimPrefix(goType(req, options, ids, qo, col), "[]")
+	for _, schema := range req.Catalog.Schemas {
+		if schema.Name == "pg_catalog" || schema.Name == "information_schema" {
+			continue
+		}
+		for _, enum := range schema.Enums {
+			enumName := enum.Name
+			if schema.Name != req.Catalog.DefaultSchema {
+				enumName = schema.Name + "_" + enum.Name
+			}
+			if elem == StructName(enumName, options) {
+				return elem + "Array"
+			}
+		}
+	}
+	return ""
+}
+
+func goInnerType(req *plugin.GenerateRequest, options *opts.Options, col *plugin.Column) string {
+	// package overrides have a higher precedence
+	if override := dbTypeOverride(options, col); override != nil {
+		return override.ShimOverride.GoType.TypeName
+	}
+	// The Null type of an overridden enum isn't generated either, so without
+	// a nullable override, matched above, nullable columns use a pointer
+	if !col.NotNull && !col.IsArray && isEnumColumn(req, col) {
+		if override := enumOverride(options, sdk.DataType(col.Type)); override != nil {
+			return "*" + override.ShimOverride.GoType.TypeName
 		}
 	}
 
@@ -58,13 +58,19 @@ func mergeImports(imps ...fileImports) [][]ImportSpec {
 }
 
 type importer struct {
-	Options *opts.Options
-	Queries []Query
-	Enums   []Enum
-	Structs []Struct
+	Options  *opts.Options
+	Queries  []Query
+	Enums    []Enum
+	TypedIDs []TypedID
+	Structs  []Struct
 }
 
 func (i *importer) usesType(typ string) bool {
+	for _, id := range i.TypedIDs {
+		if strings.HasPrefix(id.Type, typ) || strings.HasPrefix(id.NullType, typ) {
+			return true
+		}
+	}
 	for _, strct := range i.Structs {
 		for _, f := range strct.Fields {
 			if hasPrefixIgnoringSliceAndPointerPrefix(f.Type, typ) {
@@ -101,6 +107,10 @@ func (i *importer) Imports(filename string) [][]ImportSpec {
 	if i.Options.OutputBatchFileName != "" {
 		batchFileName = i.Options.OutputBatchFileName
 	}
+	registryFileName := "registry.go"
+	if i.Options.OutputRegistryFileName != "" {
+		registryFileName = i.Options.OutputRegistryFileName
+	}
 
 	switch filename {
 	case dbFileName:
@@ -113,6 +123,8 @@ func (i *importer) Imports(filename string) [][]ImportSpec {
 		return mergeImports(i.copyfromImports())
 	case batchFileName:
 		return mergeImports(i.batchImports())
+	case registryFileName:
+		return mergeImports(fileImports{})
 	default:
 		return mergeImports(i.queryImports(filename))
 	}
@@ -132,6 +144,9 @@ func (i *importer) dbImports() fileImports {
 	case opts.SQLDriverPGXV5:
 		pkg = append(pkg, ImportSpec{Path: "github.com/jackc/pgx/v5/pgconn"})
 		pkg = append(pkg, ImportSpec{Path: "github.com/jackc/pgx/v5"})
+		if i.Options.EmitRegisterTypes {
+			std = append(std, ImportSpec{Path: "fmt"})
+		}
 	default:
 		std = append(std, ImportSpec{Path: "database/sql"})
 		if i.Options.EmitPreparedQueries {
@@ -241,11 +256,32 @@ func buildImports(options *opts.Options, queries []Query, uses func(string) bool
 		if (!alreadyImported || hasPackageAlias) && uses(o.GoType.TypeName) {
 			pkg[ImportSpec{Path: o.GoType.ImportPath, ID: o.GoType.Package}] = struct{}{}
 		}
+		if uses(o.GoType.TypeName) {
+			for _, imp := range o.GoType.Imports {
+				addImport(std, pkg, imp.Path, imp.Package)
+			}
+		}
 	}
 
 	return std, pkg
 }
 
+// addImport adds a package to the standard library or the third party
+// imports, depending on its path, unless it's already imported.
+func addImport(std map[string]struct{}, pkg map[ImportSpec]struct{}, path, id string) {
+	if _, ok := pkg[ImportSpec{Path: path, ID: id}]; ok {
+		return
+	}
+	if _, ok := std[path]; ok && id == "" {
+		return
+	}
+	if id == "" && !strings.Contains(strings.Split(path, "/")[0], ".") {
+		std[path] = struct{}{}
+	} else {
+		pkg[ImportSpec{Path: path, ID: id}] = struct{}{}
+	}
+}
+
 func (i *importer) interfaceImports() fileImports {
 	std, pkg := buildImports(i.Options, i.Queries, func(name string) bool {
 		for _, q := range i.Queries {
@@ -278,6 +314,18 @@ func (i *importer) modelImports() fileImports {
 		std["fmt"] = struct{}{}
 		std["database/sql/driver"] = struct{}{}
 	}
+	if len(i.TypedIDs) > 0 {
+		std["database/sql/driver"] = struct{}{}
+	}
+	if i.Options.EmitStrictEnums && len(i.Enums) > 0 {
+		std["encoding/json"] = struct{}{}
+	}
+	if usesEnumArrays(i.Enums) || usesEnumSets(i.Enums) {
+		std["strings"] = struct{}{}
+	}
+	if usesOptional(i.Queries) {
+		std["database/sql/driver"] = struct{}{}
+	}
 
 	return sortedImports(std, pkg)
 }
@@ -314,7 +362,7 @@ func (i *importer) queryImports(filename string) fileImports {
 	std, pkg := buildImports(i.Options, gq, func(name string) bool {
 		for _, q := range gq {
 			if q.hasRetType() {
-				if q.Ret.EmitStruct() {
+				if q.Ret.DefineStruct() {
 					for _, f := range q.Ret.Struct.Fields {
 						if hasPrefixIgnoringSliceAndPointerPrefix(f.Type, name) {
 							return true
@@ -324,9 +372,28 @@ func (i *importer) queryImports(filename string) fileImports {
 				if hasPrefixIgnoringSliceAndPointerPrefix(q.Ret.Type(), name) {
 					return true
 				}
+				// Check the fields nullable embedded tables are scanned into
+				if q.Ret.IsStruct() {
+					for _, f := range q.Ret.Struct.Fields {
+						if !f.EmbedNullable {
+							continue
+						}
+						for _, embed := range f.EmbedFields {
+							if hasPrefixIgnoringSliceAndPointerPrefix(embed.Type, name) {
+								return true
+							}
+						}
+					}
+				}
+				// Check the native values decoded fields are scanned into
+				for _, c := range q.Ret.converters() {
+					if c.Decode != "" && hasPrefixIgnoringSliceAndPointerPrefix(c.Native, name) {
+						return true
+					}
+				}
 			}
 			// Check the fields of the argument struct if it's emitted
-			if q.Arg.EmitStruct() {
+			if q.Arg.DefineStruct() {
 				for _, f := range q.Arg.Struct.Fields {
 					if hasPrefixIgnoringSliceAndPointerPrefix(f.Type, name) {
 						return true
@@ -348,17 +415,17 @@ func (i *importer) queryImports(filename string) fileImports {
 			if q.hasRetType() {
 				if q.Ret.IsStruct() {
 					for _, f := range q.Ret.Struct.Fields {
-						if strings.HasPrefix(f.Type, "[]") && f.Type != "[]byte" {
+						if pqArray(f.Type, f.Column) && f.ArrayType == "" && !f.Nested && !f.hasDecoder() {
 							return true
 						}
 						for _, embed := range f.EmbedFields {
-							if strings.HasPrefix(embed.Type, "[]") && embed.Type != "[]byte" {
+							if pqArray(embed.Type, embed.Column) && embed.ArrayType == "" && !embed.hasDecoder() {
 								return true
 							}
 						}
 					}
 				} else {
-					if strings.HasPrefix(q.Ret.Type(), "[]") && q.Ret.Type() != "[]byte" {
+					if pqArray(q.Ret.Type(), q.Ret.Column) && q.Ret.ArrayType == "" && (q.Ret.Converter == nil || q.Ret.Converter.Decode == "") {
 						return true
 					}
 				}
@@ -366,12 +433,12 @@ func (i *importer) queryImports(filename string) fileImports {
 			if !q.Arg.isEmpty() {
 				if q.Arg.IsStruct() {
 					for _, f := range q.Arg.Struct.Fields {
-						if strings.HasPrefix(f.Type, "[]") && f.Type != "[]byte" && !f.HasSqlcSlice() {
+						if pqArray(f.Type, f.Column) && f.ArrayType == "" && !f.HasEncoder() {
 							return true
 						}
 					}
 				} else {
-					if strings.HasPrefix(q.Arg.Type(), "[]") && q.Arg.Type() != "[]byte" && !q.Arg.HasSqlcSlices() {
+					if pqArray(q.Arg.Type(), q.Arg.Column) && q.Arg.ArrayType == "" && (q.Arg.Converter == nil || q.Arg.Converter.Encode == "") {
 						return true
 					}
 				}
@@ -402,6 +469,14 @@ func (i *importer) queryImports(filename string) fileImports {
 		pkg[ImportSpec{Path: "github.com/lib/pq"}] = struct{}{}
 	}
 
+	var bound []Query
+	for _, q := range gq {
+		if q.Cmd != metadata.CmdCopyFrom {
+			bound = append(bound, q)
+		}
+	}
+	converterImports(bound, std, pkg)
+
 	return sortedImports(std, pkg)
 }
 
@@ -436,6 +511,7 @@ func (i *importer) copyfromImports() fileImports {
 		pkg[ImportSpec{Path: "github.com/go-sql-driver/mysql"}] = struct{}{}
 		pkg[ImportSpec{Path: "github.com/hexon/mysqltsv"}] = struct{}{}
 	}
+	converterImports(copyFromQueries, std, pkg)
 
 	return sortedImports(std, pkg)
 }
@@ -450,7 +526,7 @@ func (i *importer) batchImports() fileImports {
 	std, pkg := buildImports(i.Options, batchQueries, func(name string) bool {
 		for _, q := range batchQueries {
 			if q.hasRetType() {
-				if q.Ret.EmitStruct() {
+				if q.Ret.DefineStruct() {
 					for _, f := range q.Ret.Struct.Fields {
 						if hasPrefixIgnoringSliceAndPointerPrefix(f.Type, name) {
 							return true
@@ -460,8 +536,27 @@ func (i *importer) batchImports() fileImports {
 				if hasPrefixIgnoringSliceAndPointerPrefix(q.Ret.Type(), name) {
 					return true
 				}
+				// Check the fields nullable embedded tables are scanned into
+				if q.Ret.IsStruct() {
+					for _, f := range q.Ret.Struct.Fields {
+						if !f.EmbedNullable {
+							continue
+						}
+						for _, embed := range f.EmbedFields {
+							if hasPrefixIgnoringSliceAndPointerPrefix(embed.Type, name) {
+								return true
+							}
+						}
+					}
+				}
+				// Check the native values decoded fields are scanned into
+				for _, c := range q.Ret.converters() {
+					if c.Decode != "" && hasPrefixIgnoringSliceAndPointerPrefix(c.Native, name) {
+						return true
+					}
+				}
 			}
-			if q.Arg.EmitStruct() {
+			if q.Arg.DefineStruct() {
 				for _, f := range q.Arg.Struct.Fields {
 					if hasPrefixIgnoringSliceAndPointerPrefix(f.Type, name) {
 						return true
@@ -486,11 +581,15 @@ func (i *importer) batchImports() fileImports {
 	case opts.SQLDriverPGXV5:
 		pkg[ImportSpec{Path: "github.com/jackc/pgx/v5"}] = struct{}{}
 	}
+	converterImports(batchQueries, std, pkg)
 
 	return sortedImports(std, pkg)
 }
 
 func trimSliceAndPointerPrefix(v string) string {
+	if elem, ok := strings.CutPrefix(v, "Optional["); ok {
+		v = strings.TrimSuffix(elem, "]")
+	}
 	v = strings.TrimPrefix(v, "[]")
 	v = strings.TrimPrefix(v, "*")
 	return v
@@ -117,6 +117,16 @@ func mysqlType(req *plugin.GenerateRequest, options *opts.Options, col *plugin.C
 		for _, schema := range req.Catalog.Schemas {
 			for _, enum := range schema.Enums {
 				if enum.Name == columnType {
+					if _, ok := options.MySQLSets[enum.Name]; ok {
+						typ := StructName(enum.Name, options) + "Set"
+						if schema.Name != req.Catalog.DefaultSchema {
+							typ = StructName(schema.Name+"_"+enum.Name, options) + "Set"
+						}
+						if notNull {
+							return typ
+						}
+						return "*" + typ
+					}
 					if notNull {
 						if schema.Name == req.Catalog.DefaultSchema {
 							return StructName(enum.Name, options)
@@ -0,0 +1,97 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+)
+
+// The plugin catalog does not describe PostgreSQL domains, so the underlying
+// type of each domain must be declared in the plugin options.
+type Domain struct {
+	// name of the domain, e.g. `email` or `accounts.email`
+	Name string `json:"name" yaml:"name"`
+
+	// name of the underlying database type, e.g. `text`
+	DBType string `json:"db_type" yaml:"db_type"`
+
+	DomainSchema string `json:"-"`
+	DomainName   string `json:"-"`
+}
+
+func (d *Domain) Matches(n *plugin.Identifier, defaultSchema string) bool {
+	if n == nil {
+		return false
+	}
+	schema := n.Schema
+	if n.Schema == "" {
+		schema = defaultSchema
+	}
+	return d.DomainSchema == schema && d.DomainName == n.Name
+}
+
+// BaseType returns the underlying database type of the domain, with its
+// schema split off when it is qualified.
+func (d *Domain) BaseType() *plugin.Identifier {
+	if schema, name, ok := strings.Cut(d.DBType, "."); ok {
+		return &plugin.Identifier{Schema: schema, Name: name}
+	}
+	return &plugin.Identifier{Name: d.DBType}
+}
+
+func (d *Domain) parse(req *plugin.GenerateRequest) error {
+	if d.Name == "" {
+		return fmt.Errorf("Domain must specify a `name`")
+	}
+	if d.DBType == "" {
+		return fmt.Errorf("Domain %q must specify a `db_type`", d.Name)
+	}
+
+	schema := "public"
+	if req != nil && req.Catalog != nil {
+		schema = req.Catalog.DefaultSchema
+	}
+
+	parts := strings.Split(d.Name, ".")
+	switch len(parts) {
+	case 1:
+		d.DomainSchema = schema
+		d.DomainName = parts[0]
+	case 2:
+		d.DomainSchema = parts[0]
+		d.DomainName = parts[1]
+	default:
+		return fmt.Errorf("Domain `name` specifier %q is not the proper format, expected '[schema.]name'", d.Name)
+	}
+	return nil
+}
+
+// validateDomains makes sure that resolving a domain to its underlying type
+// always terminates.
+func validateDomains(domains []Domain) error {
+	underlying := make(map[string]string, len(domains))
+	for _, d := range domains {
+		base := d.BaseType()
+		if base.Schema == "" {
+			base.Schema = d.DomainSchema
+		}
+		underlying[d.DomainSchema+"."+d.DomainName] = base.Schema + "." + base.Name
+	}
+	for _, d := range domains {
+		seen := map[string]struct{}{}
+		name := d.DomainSchema + "." + d.DomainName
+		for {
+			if _, found := seen[name]; found {
+				return fmt.Errorf("invalid options: domain %q is defined in terms of itself", d.Name)
+			}
+			seen[name] = struct{}{}
+			next, ok := underlying[name]
+			if !ok {
+				break
+			}
+			name = next
+		}
+	}
+	return nil
+}
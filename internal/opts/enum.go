@@ -23,6 +23,30 @@ func validatePackage(sqlPackage string) error {
 	return nil
 }
 
+type EnumNaming string
+
+const (
+	EnumNamingPrefix EnumNaming = "prefix"
+	EnumNamingSuffix EnumNaming = "suffix"
+	EnumNamingValue  EnumNaming = "value"
+)
+
+var validEnumNamings = map[EnumNaming]struct{}{
+	EnumNamingPrefix: {},
+	EnumNamingSuffix: {},
+	EnumNamingValue:  {},
+}
+
+func validateEnumNaming(naming EnumNaming) error {
+	if naming == "" {
+		return nil
+	}
+	if _, found := validEnumNamings[naming]; !found {
+		return fmt.Errorf("unknown enum naming: %s", naming)
+	}
+	return nil
+}
+
 const (
 	SQLDriverPGXV4            SQLDriver = "github.com/jackc/pgx/v4"
 	SQLDriverPGXV5                      = "github.com/jackc/pgx/v5"
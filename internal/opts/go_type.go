@@ -3,6 +3,7 @@ package opts
 import (
 	"encoding/json"
 	"fmt"
+	"go/token"
 	"go/types"
 	"regexp"
 	"strings"
@@ -26,6 +27,13 @@ type ParsedGoType struct {
 	TypeName   string
 	BasicType  bool
 	StructTag  string
+	Imports    []GoImport // Imports needed by the type arguments of a generic type
+}
+
+// GoImport is a package imported by generated code.
+type GoImport struct {
+	Path    string
+	Package string // Set when the package must be imported under this name
 }
 
 func (o *GoType) MarshalJSON() ([]byte, error) {
@@ -109,6 +117,17 @@ func (gt GoType) parse() (*ParsedGoType, error) {
 		o.ImportPath = gt.Path
 		o.TypeName = gt.Name
 		o.BasicType = gt.Path == "" && gt.Package == ""
+		if open := strings.IndexByte(gt.Name, '['); open != -1 {
+			if o.BasicType {
+				return nil, fmt.Errorf("Package override `go_type` type %q instantiates a basic type", gt.Name)
+			}
+			args, imports, err := parseTypeArgs(gt.Name[open:], importNames{pkg: gt.Path})
+			if err != nil {
+				return nil, err
+			}
+			o.TypeName = gt.Name[:open] + args
+			o.Imports = imports
+		}
 		if pkg != "" {
 			o.TypeName = pkg + "." + o.TypeName
 		}
@@ -121,7 +140,155 @@ func (gt GoType) parse() (*ParsedGoType, error) {
 		return &o, nil
 	}
 
-	input := gt.Spec
+	return parseGoTypeSpec(gt.Spec)
+}
+
+// parseGoTypeSpec parses the string form of a go_type, which may be a
+// generic instantiation such as 'github.com/acme/opt.Option[string]'.
+func parseGoTypeSpec(input string) (*ParsedGoType, error) {
+	return parseScopedGoTypeSpec(input, importNames{})
+}
+
+// importNames maps the names packages are imported under in a go_type to
+// their import path.
+type importNames map[string]string
+
+// qualify makes the package of a parsed type importable under its name,
+// giving it an alias and renaming its qualifier in TypeName when another
+// package of the go_type already uses that name.
+func (n importNames) qualify(o *ParsedGoType) {
+	if o.ImportPath == "" {
+		return
+	}
+	ptr := strings.HasPrefix(o.TypeName, "*")
+	name, typ, _ := strings.Cut(strings.TrimPrefix(o.TypeName, "*"), ".")
+	if path, ok := n[name]; !ok || path == o.ImportPath {
+		n[name] = o.ImportPath
+		return
+	}
+	alias := name
+	for i := 2; n[alias] != ""; i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+	n[alias] = o.ImportPath
+	o.Package = alias
+	o.TypeName = alias + "." + typ
+	if ptr {
+		o.TypeName = "*" + o.TypeName
+	}
+}
+
+func parseScopedGoTypeSpec(input string, names importNames) (*ParsedGoType, error) {
+	open := strings.IndexByte(input, '[')
+	if open == -1 {
+		o, err := parseNamedGoTypeSpec(input)
+		if err != nil {
+			return nil, err
+		}
+		names.qualify(o)
+		return o, nil
+	}
+	if open == 0 || !strings.HasSuffix(input, "]") {
+		return nil, fmt.Errorf("Package override `go_type` specifier %q is not the proper format, expected 'package.type[type arguments]', e.g. 'github.com/acme/opt.Option[string]'", input)
+	}
+	o, err := parseNamedGoTypeSpec(input[:open])
+	if err != nil {
+		return nil, err
+	}
+	if o.BasicType {
+		return nil, fmt.Errorf("Package override `go_type` specifier %q instantiates a basic type", input)
+	}
+	names.qualify(o)
+	args, imports, err := parseTypeArgs(input[open:], names)
+	if err != nil {
+		return nil, err
+	}
+	o.TypeName += args
+	o.Imports = imports
+	return o, nil
+}
+
+// parseTypeArgs parses a type argument list such as '[string, pkg.ID]' and
+// returns it in the form used in generated code, along with the packages the
+// arguments refer to. Packages sharing the name of another package of the
+// go_type are aliased, see importNames.qualify.
+func parseTypeArgs(list string, names importNames) (string, []GoImport, error) {
+	if !strings.HasPrefix(list, "[") || !strings.HasSuffix(list, "]") {
+		return "", nil, fmt.Errorf("Package override `go_type` type arguments %q are not the proper format, expected '[type, ...]'", list)
+	}
+	var args []string
+	var imports []GoImport
+	depth, start := 0, 1
+	for i := 1; i < len(list); i++ {
+		switch list[i] {
+		case '[':
+			depth++
+			continue
+		case ']':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if i != len(list)-1 {
+				return "", nil, fmt.Errorf("Package override `go_type` type arguments %q are not the proper format, expected '[type, ...]'", list)
+			}
+		case ',':
+			if depth > 0 {
+				continue
+			}
+		default:
+			continue
+		}
+		arg := strings.TrimSpace(list[start:i])
+		start = i + 1
+
+		// Pointer and slice prefixes apply to the argument, not the package
+		var prefix string
+		for {
+			if strings.HasPrefix(arg, "*") {
+				prefix, arg = prefix+"*", arg[1:]
+			} else if strings.HasPrefix(arg, "[]") {
+				prefix, arg = prefix+"[]", arg[2:]
+			} else {
+				break
+			}
+		}
+		if arg == "" {
+			return "", nil, fmt.Errorf("Package override `go_type` type arguments %q contain an empty type", list)
+		}
+		parsed, err := parseScopedGoTypeSpec(arg, names)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, prefix+parsed.TypeName)
+		if parsed.ImportPath != "" {
+			imports = append(imports, GoImport{Path: parsed.ImportPath, Package: parsed.Package})
+		}
+		imports = append(imports, parsed.Imports...)
+	}
+	if depth != 0 {
+		return "", nil, fmt.Errorf("Package override `go_type` type arguments %q are not the proper format, expected '[type, ...]'", list)
+	}
+	return "[" + strings.Join(args, ", ") + "]", imports, nil
+}
+
+// parseGoFuncSpec parses the name of a converter function, either a function
+// of the generated package, e.g. 'toCents', or a function in another package,
+// e.g. 'github.com/acme/money.ToCents'.
+func parseGoFuncSpec(input string) (*ParsedGoType, error) {
+	if token.IsIdentifier(input) {
+		return &ParsedGoType{TypeName: input}, nil
+	}
+	lastDot := strings.LastIndex(input, ".")
+	if lastDot == -1 || strings.HasPrefix(input, "*") || strings.LastIndex(input, "/") > lastDot || !token.IsIdentifier(input[lastDot+1:]) {
+		return nil, fmt.Errorf("Package override function %q is not the proper format, expected 'package.Func', e.g. 'github.com/acme/money.ToCents'", input)
+	}
+	return parseNamedGoTypeSpec(input)
+}
+
+func parseNamedGoTypeSpec(input string) (*ParsedGoType, error) {
+	var o ParsedGoType
+
 	lastDot := strings.LastIndex(input, ".")
 	lastSlash := strings.LastIndex(input, "/")
 	typename := input
@@ -162,7 +329,7 @@ func (gt GoType) parse() (*ParsedGoType, error) {
 	isPointer := input[0] == '*'
 	if isPointer {
 		o.ImportPath = o.ImportPath[1:]
-		o.TypeName = "*" + o.TypeName
+		o.TypeName = "*" + strings.TrimPrefix(o.TypeName, "*")
 	}
 	return &o, nil
 }
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"maps"
 	"path/filepath"
+	"strings"
 
+	"github.com/sqlc-dev/plugin-sdk-go/pattern"
 	"github.com/sqlc-dev/plugin-sdk-go/plugin"
 )
 
@@ -24,11 +26,26 @@ type Options struct {
 	EmitPointersForNullTypes    bool              `json:"emit_pointers_for_null_types" yaml:"emit_pointers_for_null_types"`
 	EmitEnumValidMethod         bool              `json:"emit_enum_valid_method,omitempty" yaml:"emit_enum_valid_method"`
 	EmitAllEnumValues           bool              `json:"emit_all_enum_values,omitempty" yaml:"emit_all_enum_values"`
+	EmitStrictEnums             bool              `json:"emit_strict_enums,omitempty" yaml:"emit_strict_enums"`
+	EnumNaming                  EnumNaming        `json:"enum_naming,omitempty" yaml:"enum_naming"`
 	EmitSqlAsComment            bool              `json:"emit_sql_as_comment,omitempty" yaml:"emit_sql_as_comment"`
+	EmitDomainTypes             bool              `json:"emit_domain_types,omitempty" yaml:"emit_domain_types"`
+	EmitModelDescriptors        bool              `json:"emit_model_descriptors,omitempty" yaml:"emit_model_descriptors"`
+	EmitModelConversions        bool              `json:"emit_model_conversions,omitempty" yaml:"emit_model_conversions"`
+	EmitRegisterTypes           bool              `json:"emit_register_types,omitempty" yaml:"emit_register_types"`
+	EmitQueryRegistry           bool              `json:"emit_query_registry,omitempty" yaml:"emit_query_registry"`
+	EmitQueryFingerprints       bool              `json:"emit_query_fingerprints,omitempty" yaml:"emit_query_fingerprints"`
+	EmitSqlCommenter            bool              `json:"emit_sql_commenter,omitempty" yaml:"emit_sql_commenter"`
+	DedupeQueryStructs          bool              `json:"dedupe_query_structs,omitempty" yaml:"dedupe_query_structs"`
+	EmitModelParams             bool              `json:"emit_model_params,omitempty" yaml:"emit_model_params"`
+	EmitOptionalParams          bool              `json:"emit_optional_params,omitempty" yaml:"emit_optional_params"`
 	JsonTagsCaseStyle           string            `json:"json_tags_case_style,omitempty" yaml:"json_tags_case_style"`
 	Package                     string            `json:"package" yaml:"package"`
 	Out                         string            `json:"out" yaml:"out"`
 	Overrides                   []Override        `json:"overrides,omitempty" yaml:"overrides"`
+	TypePresets                 []string          `json:"type_presets,omitempty" yaml:"type_presets"`
+	Domains                     []Domain          `json:"domains,omitempty" yaml:"domains"`
+	TypedIDs                    *TypedIDs         `json:"typed_ids,omitempty" yaml:"typed_ids"`
 	Rename                      map[string]string `json:"rename,omitempty" yaml:"rename"`
 	SqlPackage                  string            `json:"sql_package" yaml:"sql_package"`
 	SqlDriver                   string            `json:"sql_driver" yaml:"sql_driver"`
@@ -37,6 +54,7 @@ type Options struct {
 	OutputModelsFileName        string            `json:"output_models_file_name,omitempty" yaml:"output_models_file_name"`
 	OutputQuerierFileName       string            `json:"output_querier_file_name,omitempty" yaml:"output_querier_file_name"`
 	OutputCopyfromFileName      string            `json:"output_copyfrom_file_name,omitempty" yaml:"output_copyfrom_file_name"`
+	OutputRegistryFileName      string            `json:"output_registry_file_name,omitempty" yaml:"output_registry_file_name"`
 	OutputFilesSuffix           string            `json:"output_files_suffix,omitempty" yaml:"output_files_suffix"`
 	InflectionExcludeTableNames []string          `json:"inflection_exclude_table_names,omitempty" yaml:"inflection_exclude_table_names"`
 	QueryParameterLimit         *int32            `json:"query_parameter_limit,omitempty" yaml:"query_parameter_limit"`
@@ -45,6 +63,30 @@ type Options struct {
 	BuildTags                   string            `json:"build_tags,omitempty" yaml:"build_tags"`
 	Initialisms                 *[]string         `json:"initialisms,omitempty" yaml:"initialisms"`
 
+	// Go names for enum values, e.g. {"status": {"A-1": "alpha_1"}}
+	EnumValueRename map[string]map[string]string `json:"enum_value_rename,omitempty" yaml:"enum_value_rename"`
+
+	// MySQL SET columns, e.g. `users.roles`. sqlc reports their values like
+	// those of an ENUM, so they can't be told apart otherwise.
+	MySQLSetColumns []string `json:"mysql_set_columns,omitempty" yaml:"mysql_set_columns"`
+
+	// Names of the enums sqlc creates for the MySQL SET columns, e.g.
+	// `users_roles`
+	MySQLSets map[string]struct{} `json:"-" yaml:"-"`
+
+	// Fail generation when a column or parameter has no Go type mapping and
+	// falls back to interface{}, except for those matching a pattern of the
+	// allowlist: a `table.column` or `Query.name` location, or a db type.
+	StrictTypes      bool             `json:"strict_types,omitempty" yaml:"strict_types"`
+	StrictTypesAllow []string         `json:"strict_types_allow,omitempty" yaml:"strict_types_allow"`
+	StrictTypesMatch []*pattern.Match `json:"-" yaml:"-"`
+
+	// Map columns to the Go types pgx/v5 scans natively, e.g. time.Time
+	// instead of pgtype.Timestamptz, and nullable columns to pointers.
+	// Numerics, intervals and times of day keep their pgtype types, as pgx
+	// would scan an interval of '1 mon' into a time.Duration of 720 hours.
+	PgxNativeTypes bool `json:"pgx_native_types,omitempty" yaml:"pgx_native_types"`
+
 	InitialismsMap map[string]struct{} `json:"-" yaml:"-"`
 }
 
@@ -71,6 +113,12 @@ func Parse(req *plugin.GenerateRequest) (*Options, error) {
 		}
 		maps.Copy(options.Rename, global.Rename)
 	}
+	// Presets go last, so the overrides written out take precedence
+	presets, err := expandTypePresets(req, options)
+	if err != nil {
+		return nil, err
+	}
+	options.Overrides = append(options.Overrides, presets...)
 	return options, nil
 }
 
@@ -95,6 +143,53 @@ func parseOpts(req *plugin.GenerateRequest) (*Options, error) {
 		if err := options.Overrides[i].parse(req); err != nil {
 			return nil, err
 		}
+		// Unlike global overrides, package overrides only ever see one engine
+		if engine := options.Overrides[i].Engine; engine != "" && engine != req.GetSettings().GetEngine() {
+			return nil, fmt.Errorf("invalid options: override for %s is scoped to engine %q and can never match a %q package", options.Overrides[i].target(), engine, req.GetSettings().GetEngine())
+		}
+	}
+
+	for i := range options.Domains {
+		if err := options.Domains[i].parse(req); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateDomains(options.Domains); err != nil {
+		return nil, err
+	}
+
+	if options.TypedIDs != nil {
+		if err := options.TypedIDs.parse(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(options.StrictTypesAllow) > 0 && !options.StrictTypes {
+		return nil, fmt.Errorf("invalid options: strict_types_allow requires strict_types")
+	}
+	for _, allow := range options.StrictTypesAllow {
+		match, err := pattern.MatchCompile(allow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid options: strict_types_allow: %w", err)
+		}
+		options.StrictTypesMatch = append(options.StrictTypesMatch, match)
+	}
+
+	if len(options.MySQLSetColumns) > 0 {
+		if req.Settings.Engine != "mysql" {
+			return nil, fmt.Errorf("invalid options: mysql_set_columns is only supported by the mysql engine")
+		}
+		options.MySQLSets = map[string]struct{}{}
+		for _, column := range options.MySQLSetColumns {
+			table, name, found := strings.Cut(column, ".")
+			if !found || table == "" || name == "" || strings.Contains(name, ".") {
+				return nil, fmt.Errorf("invalid options: mysql_set_columns entry %q is not the proper format, expected 'tablename.colname'", column)
+			}
+			if !hasEnum(req.GetCatalog(), table+"_"+name) {
+				return nil, fmt.Errorf("invalid options: mysql_set_columns entry %q matches no SET column", column)
+			}
+			options.MySQLSets[table+"_"+name] = struct{}{}
+		}
 	}
 
 	if options.SqlPackage != "" {
@@ -127,6 +222,19 @@ func parseOpts(req *plugin.GenerateRequest) (*Options, error) {
 	return &options, nil
 }
 
+// hasEnum reports whether the catalog has an enum of the given name. sqlc
+// names the enums of MySQL ENUM and SET columns `table_column`.
+func hasEnum(catalog *plugin.Catalog, name string) bool {
+	for _, schema := range catalog.GetSchemas() {
+		for _, enum := range schema.GetEnums() {
+			if enum.GetName() == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func parseGlobalOpts(req *plugin.GenerateRequest) (*GlobalOptions, error) {
 	var options GlobalOptions
 	if len(req.GlobalOptions) == 0 {
@@ -135,11 +243,18 @@ func parseGlobalOpts(req *plugin.GenerateRequest) (*GlobalOptions, error) {
 	if err := json.Unmarshal(req.GlobalOptions, &options); err != nil {
 		return nil, fmt.Errorf("unmarshalling global options: %w", err)
 	}
+	// Global overrides are shared by every package, so drop the ones scoped
+	// to another engine
+	overrides := options.Overrides[:0]
 	for i := range options.Overrides {
 		if err := options.Overrides[i].parse(req); err != nil {
 			return nil, err
 		}
+		if engine := options.Overrides[i].Engine; engine == "" || engine == req.GetSettings().GetEngine() {
+			overrides = append(overrides, options.Overrides[i])
+		}
 	}
+	options.Overrides = overrides
 	return &options, nil
 }
 
@@ -147,6 +262,15 @@ func ValidateOpts(opts *Options) error {
 	if opts.EmitMethodsWithDbArgument && opts.EmitPreparedQueries {
 		return fmt.Errorf("invalid options: emit_methods_with_db_argument and emit_prepared_queries options are mutually exclusive")
 	}
+	if err := validateEnumNaming(opts.EnumNaming); err != nil {
+		return fmt.Errorf("invalid options: %s", err)
+	}
+	if opts.EmitRegisterTypes && opts.SqlPackage != SQLPackagePGXV5 {
+		return fmt.Errorf("invalid options: emit_register_types requires sql_package %s", SQLPackagePGXV5)
+	}
+	if opts.PgxNativeTypes && opts.SqlPackage != SQLPackagePGXV5 {
+		return fmt.Errorf("invalid options: pgx_native_types requires sql_package %s", SQLPackagePGXV5)
+	}
 	if *opts.QueryParameterLimit < 0 {
 		return fmt.Errorf("invalid options: query parameter limit must not be negative")
 	}
@@ -13,6 +13,11 @@ type Override struct {
 	// name of the golang type to use, e.g. `github.com/segmentio/ksuid.KSUID`
 	GoType GoType `json:"go_type" yaml:"go_type"`
 
+	// name of the golang type to use for each element of an array column or
+	// sqlc.slice parameter, e.g. `github.com/google/uuid.UUID`, instead of
+	// go_type. The column gets a slice of it with the array's dimensions.
+	GoElementType GoType `json:"go_element_type" yaml:"go_element_type"`
+
 	// additional Go struct tags to add to this field, in raw Go struct tag form, e.g. `validate:"required" x:"y,z"`
 	// see https://github.com/sqlc-dev/sqlc/issues/534
 	GoStructTag GoStructTag `json:"go_struct_tag" yaml:"go_struct_tag"`
@@ -21,7 +26,8 @@ type Override struct {
 	DBType                  string `json:"db_type" yaml:"db_type"`
 	Deprecated_PostgresType string `json:"postgres_type" yaml:"postgres_type"`
 
-	// for global overrides only when two different engines are in use
+	// engine the override applies to, e.g. `postgresql`; global overrides for
+	// other engines are ignored
 	Engine string `json:"engine,omitempty" yaml:"engine"`
 
 	// True if the GoType should override if the matching type is nullable
@@ -33,17 +39,37 @@ type Override struct {
 	// Deprecated. Use the `nullable` property instead
 	Deprecated_Null bool `json:"null" yaml:"null"`
 
+	// functions converting between the Go type and the type the column has
+	// without overrides, e.g. `github.com/acme/money.ToCents`, for Go types
+	// the driver can't bind or scan. Encode has the signature
+	// func(GoType) Native and decode func(Native) (GoType, error).
+	Encode string `json:"encode" yaml:"encode"`
+	Decode string `json:"decode" yaml:"decode"`
+
 	// fully qualified name of the column, e.g. `accounts.id`
 	Column string `json:"column" yaml:"column"`
 
+	// name of the query, e.g. `ListInvoices`, used together with `param` or
+	// `result` to override the type of a parameter or result column
+	Query  string `json:"query" yaml:"query"`
+	Param  string `json:"param" yaml:"param"`
+	Result string `json:"result" yaml:"result"`
+
 	ColumnName   *pattern.Match `json:"-"`
 	TableCatalog *pattern.Match `json:"-"`
 	TableSchema  *pattern.Match `json:"-"`
 	TableRel     *pattern.Match `json:"-"`
+	QueryName    *pattern.Match `json:"-"`
+	ParamName    *pattern.Match `json:"-"`
+	ResultName   *pattern.Match `json:"-"`
 	GoImportPath string         `json:"-"`
 	GoPackage    string         `json:"-"`
 	GoTypeName   string         `json:"-"`
 	GoBasicType  bool           `json:"-"`
+	GoImports    []GoImport     `json:"-"`
+	GoElement    bool           `json:"-"` // The Go type is set by go_element_type
+	GoEncode     *ParsedGoType  `json:"-"`
+	GoDecode     *ParsedGoType  `json:"-"`
 
 	// Parsed form of GoStructTag, e.g. {"validate:", "required"}
 	GoStructTags map[string]string `json:"-"`
@@ -76,6 +102,29 @@ func (o *Override) Matches(n *plugin.Identifier, defaultSchema string) bool {
 	return true
 }
 
+// MatchesParam reports whether the override applies to the named parameter
+// of a query.
+func (o *Override) MatchesParam(query, param string) bool {
+	return o.QueryName != nil && o.ParamName != nil && o.QueryName.MatchString(query) && o.ParamName.MatchString(param)
+}
+
+// MatchesResult reports whether the override applies to the named result
+// column of a query.
+func (o *Override) MatchesResult(query, column string) bool {
+	return o.QueryName != nil && o.ResultName != nil && o.QueryName.MatchString(query) && o.ResultName.MatchString(column)
+}
+
+// target describes what the override applies to, for use in error messages.
+func (o *Override) target() string {
+	if o.Query != "" {
+		return fmt.Sprintf("query %q", o.Query)
+	}
+	if o.Column != "" {
+		return fmt.Sprintf("column %q", o.Column)
+	}
+	return fmt.Sprintf("db_type %q", o.DBType)
+}
+
 func (o *Override) parse(req *plugin.GenerateRequest) (err error) {
 	// validate deprecated postgres_type field
 	if o.Deprecated_PostgresType != "" {
@@ -92,6 +141,16 @@ func (o *Override) parse(req *plugin.GenerateRequest) (err error) {
 		o.Nullable = true
 	}
 
+	// validate Engine
+	switch o.Engine {
+	case "", "postgresql", "mysql", "sqlite":
+	default:
+		return fmt.Errorf("Override `engine` %q is not valid, expected one of 'postgresql', 'mysql' or 'sqlite'", o.Engine)
+	}
+	if o.Unsigned && o.Engine != "" && o.Engine != "mysql" {
+		return fmt.Errorf("Override for %s can never match: `unsigned` types only exist in mysql, not %s", o.target(), o.Engine)
+	}
+
 	schema := "public"
 	if req != nil && req.Catalog != nil {
 		schema = req.Catalog.DefaultSchema
@@ -99,10 +158,33 @@ func (o *Override) parse(req *plugin.GenerateRequest) (err error) {
 
 	// validate option combinations
 	switch {
+	case o.Query != "" && (o.Column != "" || o.DBType != ""):
+		return fmt.Errorf("Override specifying `query` (%q) together with `column` or `db_type` is not valid.", o.Query)
+	case o.Query == "" && (o.Param != "" || o.Result != ""):
+		return fmt.Errorf("Override specifying `param` or `result` must also specify `query`")
+	case o.Query != "" && (o.Param == "") == (o.Result == ""):
+		return fmt.Errorf("Override specifying `query` (%q) must specify one of either `param` or `result`", o.Query)
 	case o.Column != "" && o.DBType != "":
 		return fmt.Errorf("Override specifying both `column` (%q) and `db_type` (%q) is not valid.", o.Column, o.DBType)
-	case o.Column == "" && o.DBType == "":
-		return fmt.Errorf("Override must specify one of either `column` or `db_type`")
+	case o.Column == "" && o.DBType == "" && o.Query == "":
+		return fmt.Errorf("Override must specify one of either `column`, `db_type` or `query`")
+	}
+
+	// validate Query
+	if o.Query != "" {
+		if o.QueryName, err = pattern.MatchCompile(o.Query); err != nil {
+			return err
+		}
+		if o.Param != "" {
+			if o.ParamName, err = pattern.MatchCompile(o.Param); err != nil {
+				return err
+			}
+		}
+		if o.Result != "" {
+			if o.ResultName, err = pattern.MatchCompile(o.Result); err != nil {
+				return err
+			}
+		}
 	}
 
 	// validate Column
@@ -147,8 +229,19 @@ func (o *Override) parse(req *plugin.GenerateRequest) (err error) {
 		}
 	}
 
-	// validate GoType
-	parsed, err := o.GoType.parse()
+	// validate GoType and GoElementType
+	goType := o.GoType
+	if o.GoElementType != (GoType{}) {
+		if o.GoType != (GoType{}) {
+			return fmt.Errorf("Override for %s must specify only one of `go_type` or `go_element_type`", o.target())
+		}
+		if o.Encode != "" || o.Decode != "" {
+			return fmt.Errorf("Override for %s can't use `encode` or `decode` with `go_element_type`", o.target())
+		}
+		goType = o.GoElementType
+		o.GoElement = true
+	}
+	parsed, err := goType.parse()
 	if err != nil {
 		return err
 	}
@@ -156,6 +249,19 @@ func (o *Override) parse(req *plugin.GenerateRequest) (err error) {
 	o.GoPackage = parsed.Package
 	o.GoTypeName = parsed.TypeName
 	o.GoBasicType = parsed.BasicType
+	o.GoImports = parsed.Imports
+
+	// validate Encode and Decode
+	if o.Encode != "" {
+		if o.GoEncode, err = parseGoFuncSpec(o.Encode); err != nil {
+			return err
+		}
+	}
+	if o.Decode != "" {
+		if o.GoDecode, err = parseGoFuncSpec(o.Decode); err != nil {
+			return err
+		}
+	}
 
 	// validate GoStructTag
 	tags, err := o.GoStructTag.parse()
@@ -1,9 +1,11 @@
 package opts
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
 )
 
 func TestTypeOverrides(t *testing.T) {
@@ -12,6 +14,7 @@ func TestTypeOverrides(t *testing.T) {
 		pkg      string
 		typeName string
 		basic    bool
+		imports  []GoImport
 	}{
 		{
 			Override{
@@ -21,6 +24,18 @@ func TestTypeOverrides(t *testing.T) {
 			"github.com/segmentio/ksuid",
 			"ksuid.KSUID",
 			false,
+			nil,
+		},
+		{
+			Override{
+				DBType:   "timestamptz",
+				Nullable: true,
+				GoType:   GoType{Spec: "*time.Time"},
+			},
+			"time",
+			"*time.Time",
+			false,
+			nil,
 		},
 		// TODO: Add test for struct pointers
 		//
@@ -41,6 +56,7 @@ func TestTypeOverrides(t *testing.T) {
 			"",
 			"string",
 			true,
+			nil,
 		},
 		{
 			Override{
@@ -50,6 +66,37 @@ func TestTypeOverrides(t *testing.T) {
 			"time",
 			"time.Time",
 			false,
+			nil,
+		},
+		{
+			Override{
+				DBType: "text",
+				GoType: GoType{Spec: "github.com/acme/opt.Option[*github.com/acme/ids.ID, []string]"},
+			},
+			"github.com/acme/opt",
+			"opt.Option[*ids.ID, []string]",
+			false,
+			[]GoImport{{Path: "github.com/acme/ids"}},
+		},
+		{
+			Override{
+				DBType: "text",
+				GoType: GoType{Spec: "github.com/acme/opt.Option[github.com/other/opt.ID, github.com/acme/opt.Option[*github.com/third/opt.ID]]"},
+			},
+			"github.com/acme/opt",
+			"opt.Option[opt2.ID, opt.Option[*opt3.ID]]",
+			false,
+			[]GoImport{{Path: "github.com/other/opt", Package: "opt2"}, {Path: "github.com/acme/opt"}, {Path: "github.com/third/opt", Package: "opt3"}},
+		},
+		{
+			Override{
+				DBType: "text",
+				GoType: GoType{Path: "github.com/acme/opt", Name: "Option[github.com/other/opt.ID]"},
+			},
+			"github.com/acme/opt",
+			"opt.Option[opt2.ID]",
+			false,
+			[]GoImport{{Path: "github.com/other/opt", Package: "opt2"}},
 		},
 	} {
 		tt := test
@@ -66,6 +113,9 @@ func TestTypeOverrides(t *testing.T) {
 			if diff := cmp.Diff(tt.basic, tt.override.GoBasicType); diff != "" {
 				t.Errorf("basic mismatch;\n%s", diff)
 			}
+			if diff := cmp.Diff(tt.imports, tt.override.GoImports); diff != "" {
+				t.Errorf("imports mismatch;\n%s", diff)
+			}
 		})
 	}
 	for _, test := range []struct {
@@ -86,6 +136,44 @@ func TestTypeOverrides(t *testing.T) {
 			},
 			"Package override `go_type` specifier \"untyped rune\" is not a Go basic type e.g. 'string'",
 		},
+		{
+			Override{
+				Query:  "ListInvoices",
+				GoType: GoType{Spec: "int"},
+			},
+			"Override specifying `query` (\"ListInvoices\") must specify one of either `param` or `result`",
+		},
+		{
+			Override{
+				Result: "total",
+				GoType: GoType{Spec: "int64"},
+			},
+			"Override specifying `param` or `result` must also specify `query`",
+		},
+		{
+			Override{
+				DBType: "bigint",
+				GoType: GoType{Spec: "time.Duration"},
+				Decode: "github.com/acme/durations",
+			},
+			"Package override function \"github.com/acme/durations\" is not the proper format, expected 'package.Func', e.g. 'github.com/acme/money.ToCents'",
+		},
+		{
+			Override{
+				Column:        "users.tags",
+				GoType:        GoType{Spec: "[]string"},
+				GoElementType: GoType{Spec: "string"},
+			},
+			"Override for column \"users.tags\" must specify only one of `go_type` or `go_element_type`",
+		},
+		{
+			Override{
+				Column:        "users.tags",
+				GoElementType: GoType{Spec: "string"},
+				Encode:        "github.com/acme/tags.Encode",
+			},
+			"Override for column \"users.tags\" can't use `encode` or `decode` with `go_element_type`",
+		},
 	} {
 		tt := test
 		t.Run(tt.override.GoType.Spec, func(t *testing.T) {
@@ -100,6 +188,90 @@ func TestTypeOverrides(t *testing.T) {
 	}
 }
 
+func TestGlobalOverrideEngine(t *testing.T) {
+	req := &plugin.GenerateRequest{
+		Settings:      &plugin.Settings{Engine: "mysql"},
+		Catalog:       &plugin.Catalog{DefaultSchema: "public"},
+		PluginOptions: []byte(`{"package": "db"}`),
+		GlobalOptions: []byte(`{"overrides": [
+			{"db_type": "uuid", "go_type": "github.com/google/uuid.UUID", "engine": "postgresql"},
+			{"db_type": "json", "go_type": "encoding/json.RawMessage", "engine": "mysql"},
+			{"db_type": "text", "go_type": "string"}
+		]}`),
+	}
+	options, err := Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, o := range options.Overrides {
+		types = append(types, o.DBType)
+	}
+	if diff := cmp.Diff([]string{"json", "text"}, types); diff != "" {
+		t.Errorf("overrides mismatch;\n%s", diff)
+	}
+
+	for _, test := range []struct {
+		name    string
+		options string
+		global  string
+		err     string
+	}{
+		{
+			"unknown engine",
+			`{"package": "db"}`,
+			`{"overrides": [{"db_type": "uuid", "go_type": "string", "engine": "postgres"}]}`,
+			"Override `engine` \"postgres\" is not valid, expected one of 'postgresql', 'mysql' or 'sqlite'",
+		},
+		{
+			"unsigned outside mysql",
+			`{"package": "db"}`,
+			`{"overrides": [{"db_type": "integer", "go_type": "uint32", "unsigned": true, "engine": "sqlite"}]}`,
+			"Override for db_type \"integer\" can never match: `unsigned` types only exist in mysql, not sqlite",
+		},
+		{
+			"set column format",
+			`{"package": "db", "mysql_set_columns": ["perms"]}`,
+			``,
+			"invalid options: mysql_set_columns entry \"perms\" is not the proper format, expected 'tablename.colname'",
+		},
+		{
+			"set column missing",
+			`{"package": "db", "mysql_set_columns": ["users.perms"]}`,
+			``,
+			"invalid options: mysql_set_columns entry \"users.perms\" matches no SET column",
+		},
+		{
+			"typed ID foreign keys",
+			`{"package": "db", "typed_ids": {"columns": "*.id", "foreign_keys": "*_*_id"}}`,
+			``,
+			"invalid options: typed_ids `foreign_keys` pattern \"*_*_id\" must contain a single '*' standing for the table name, e.g. '*_id'",
+		},
+		{
+			"package override for other engine",
+			`{"package": "db", "overrides": [{"column": "users.id", "go_type": "string", "engine": "postgresql"}]}`,
+			``,
+			"invalid options: override for column \"users.id\" is scoped to engine \"postgresql\" and can never match a \"mysql\" package",
+		},
+	} {
+		tt := test
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(&plugin.GenerateRequest{
+				Settings:      &plugin.Settings{Engine: "mysql"},
+				Catalog:       &plugin.Catalog{DefaultSchema: "public"},
+				PluginOptions: []byte(tt.options),
+				GlobalOptions: []byte(tt.global),
+			})
+			if err == nil {
+				t.Fatalf("expected parse to fail; got nil")
+			}
+			if diff := cmp.Diff(tt.err, err.Error()); diff != "" {
+				t.Errorf("error mismatch;\n%s", diff)
+			}
+		})
+	}
+}
+
 func FuzzOverride(f *testing.F) {
 	for _, spec := range []string{
 		"string",
@@ -115,3 +287,56 @@ func FuzzOverride(f *testing.F) {
 		o.parse(nil)
 	})
 }
+
+func TestTypePresets(t *testing.T) {
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog:  &plugin.Catalog{DefaultSchema: "public"},
+		PluginOptions: []byte(`{"package": "db", "sql_package": "pgx/v5", "type_presets": ["google_uuid", "netip"], "overrides": [
+			{"db_type": "uuid", "go_type": "string"}
+		]}`),
+	}
+	options, err := Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, o := range options.Overrides {
+		got = append(got, fmt.Sprintf("%s %v %s", o.DBType, o.Nullable, o.GoTypeName))
+	}
+	want := []string{
+		"uuid false string",
+		"uuid false uuid.UUID",
+		"uuid true uuid.NullUUID",
+		"inet false netip.Addr",
+		"inet true *netip.Addr",
+		"cidr false netip.Prefix",
+		"cidr true *netip.Prefix",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("overrides mismatch;\n%s", diff)
+	}
+
+	for _, tt := range []struct {
+		options string
+		err     string
+	}{
+		{
+			`{"package": "db", "type_presets": ["uuid"]}`,
+			"invalid options: unknown type preset \"uuid\", expected one of 'google_uuid', 'netip', 'shopspring_decimal', 'stdlib_time'",
+		},
+		{
+			`{"package": "db", "type_presets": ["netip"]}`,
+			"invalid options: type preset \"netip\" is not available for the postgresql engine with sql_package database/sql",
+		},
+	} {
+		req.PluginOptions = []byte(tt.options)
+		_, err := Parse(req)
+		if err == nil {
+			t.Fatalf("expected parse to fail; got nil")
+		}
+		if diff := cmp.Diff(tt.err, err.Error()); diff != "" {
+			t.Errorf("error mismatch;\n%s", diff)
+		}
+	}
+}
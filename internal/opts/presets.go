@@ -0,0 +1,99 @@
+package opts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+)
+
+// A typePreset returns the overrides of a preset for an engine and SQL
+// package, or false when the preset doesn't apply to them.
+type typePreset func(engine, sqlPackage string) ([]Override, bool)
+
+var typePresets = map[string]typePreset{
+	"google_uuid": func(engine, sqlPackage string) ([]Override, bool) {
+		if engine != "postgresql" {
+			return nil, false
+		}
+		return presetOverrides([]string{"uuid"}, "github.com/google/uuid.UUID", "github.com/google/uuid.NullUUID"), true
+	},
+	"shopspring_decimal": func(engine, sqlPackage string) ([]Override, bool) {
+		var dbTypes []string
+		switch engine {
+		case "postgresql":
+			dbTypes = []string{"numeric", "pg_catalog.numeric"}
+		case "mysql":
+			dbTypes = []string{"decimal", "dec", "fixed"}
+		case "sqlite":
+			dbTypes = []string{"decimal", "numeric"}
+		}
+		return presetOverrides(dbTypes, "github.com/shopspring/decimal.Decimal", "github.com/shopspring/decimal.NullDecimal"), true
+	},
+	// time.Time for NOT NULL columns and *time.Time for the others, instead
+	// of the pgtype and sql.NullTime wrappers.
+	"stdlib_time": func(engine, sqlPackage string) ([]Override, bool) {
+		var dbTypes []string
+		switch engine {
+		case "postgresql":
+			dbTypes = []string{"date", "pg_catalog.timestamp", "pg_catalog.timestamptz", "timestamptz"}
+		case "mysql":
+			dbTypes = []string{"date", "datetime", "timestamp"}
+		case "sqlite":
+			dbTypes = []string{"date", "datetime", "timestamp"}
+		}
+		return presetOverrides(dbTypes, "time.Time", "*time.Time"), true
+	},
+	// Only pgx/v5 scans network addresses into net/netip types.
+	"netip": func(engine, sqlPackage string) ([]Override, bool) {
+		if engine != "postgresql" || sqlPackage != SQLPackagePGXV5 {
+			return nil, false
+		}
+		overrides := presetOverrides([]string{"inet"}, "net/netip.Addr", "*net/netip.Addr")
+		return append(overrides, presetOverrides([]string{"cidr"}, "net/netip.Prefix", "*net/netip.Prefix")...), true
+	},
+}
+
+func presetOverrides(dbTypes []string, goType, nullGoType string) []Override {
+	var overrides []Override
+	for _, dbType := range dbTypes {
+		overrides = append(overrides,
+			Override{DBType: dbType, GoType: GoType{Spec: goType}},
+			Override{DBType: dbType, GoType: GoType{Spec: nullGoType}, Nullable: true},
+		)
+	}
+	return overrides
+}
+
+// expandTypePresets returns the overrides of the type_presets option.
+func expandTypePresets(req *plugin.GenerateRequest, options *Options) ([]Override, error) {
+	engine := req.GetSettings().GetEngine()
+	var overrides []Override
+	for _, name := range options.TypePresets {
+		preset, ok := typePresets[name]
+		if !ok {
+			names := make([]string, 0, len(typePresets))
+			for name := range typePresets {
+				names = append(names, "'"+name+"'")
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("invalid options: unknown type preset %q, expected one of %s", name, strings.Join(names, ", "))
+		}
+		sqlPackage := options.SqlPackage
+		if sqlPackage == "" {
+			sqlPackage = SQLPackageStandard
+		}
+		presetOverrides, ok := preset(engine, sqlPackage)
+		if !ok {
+			return nil, fmt.Errorf("invalid options: type preset %q is not available for the %s engine with sql_package %s", name, engine, sqlPackage)
+		}
+		for i := range presetOverrides {
+			if err := presetOverrides[i].parse(req); err != nil {
+				return nil, fmt.Errorf("type preset %q: %w", name, err)
+			}
+		}
+		overrides = append(overrides, presetOverrides...)
+	}
+	return overrides, nil
+}
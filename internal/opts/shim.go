@@ -60,6 +60,7 @@ type ShimGoType struct {
 	TypeName   string
 	BasicType  bool
 	StructTags map[string]string
+	Imports    []GoImport
 }
 
 func shimGoType(o *Override) *ShimGoType {
@@ -73,5 +74,6 @@ func shimGoType(o *Override) *ShimGoType {
 		TypeName:   o.GoTypeName,
 		BasicType:  o.GoBasicType,
 		StructTags: o.GoStructTags,
+		Imports:    o.GoImports,
 	}
 }
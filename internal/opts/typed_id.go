@@ -0,0 +1,52 @@
+package opts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sqlc-dev/plugin-sdk-go/pattern"
+)
+
+// TypedIDs gives the ID column of each table a distinct Go type, which is
+// also used by the columns referencing the table by naming convention.
+type TypedIDs struct {
+	// pattern matching the ID columns as `table.column`, e.g. `*.id`
+	Columns string `json:"columns" yaml:"columns"`
+
+	// pattern matching the columns referencing a table's ID, where the single
+	// `*` stands for the table's singular name, e.g. `*_id`
+	ForeignKeys string `json:"foreign_keys" yaml:"foreign_keys"`
+
+	ColumnPattern    *pattern.Match `json:"-"`
+	ForeignKeyPrefix string         `json:"-"`
+	ForeignKeySuffix string         `json:"-"`
+}
+
+func (t *TypedIDs) parse() (err error) {
+	if t.Columns == "" {
+		return fmt.Errorf("invalid options: typed_ids must specify `columns`")
+	}
+	if t.ColumnPattern, err = pattern.MatchCompile(t.Columns); err != nil {
+		return fmt.Errorf("invalid options: typed_ids `columns`: %w", err)
+	}
+	if t.ForeignKeys != "" {
+		prefix, suffix, _ := strings.Cut(t.ForeignKeys, "*")
+		if strings.Count(t.ForeignKeys, "*") != 1 || strings.ContainsAny(t.ForeignKeys, "?\\") {
+			return fmt.Errorf("invalid options: typed_ids `foreign_keys` pattern %q must contain a single '*' standing for the table name, e.g. '*_id'", t.ForeignKeys)
+		}
+		t.ForeignKeyPrefix, t.ForeignKeySuffix = prefix, suffix
+	}
+	return nil
+}
+
+// ForeignKeyTable returns the name of the table a column references by
+// naming convention.
+func (t *TypedIDs) ForeignKeyTable(column string) (string, bool) {
+	if t.ForeignKeys == "" || len(column) <= len(t.ForeignKeyPrefix)+len(t.ForeignKeySuffix) {
+		return "", false
+	}
+	if !strings.HasPrefix(column, t.ForeignKeyPrefix) || !strings.HasSuffix(column, t.ForeignKeySuffix) {
+		return "", false
+	}
+	return column[len(t.ForeignKeyPrefix) : len(column)-len(t.ForeignKeySuffix)], true
+}
@@ -38,7 +38,10 @@ func postgresType(req *plugin.GenerateRequest, options *opts.Options, col *plugi
 	columnType := sdk.DataType(col.Type)
 	notNull := col.NotNull || col.IsArray
 	driver := parseDriver(options.SqlPackage)
-	emitPointersForNull := driver.IsPGX() && options.EmitPointersForNullTypes
+	// pgtype wrappers are only kept for the types pgx/v5 can't scan into a
+	// plain Go type
+	pgxWrappers := driver == opts.SQLDriverPGXV5 && !options.PgxNativeTypes
+	emitPointersForNull := driver.IsPGX() && (options.EmitPointersForNullTypes || options.PgxNativeTypes)
 
 	switch columnType {
 	case "serial", "serial4", "pg_catalog.serial4":
@@ -201,7 +204,7 @@ func postgresType(req *plugin.GenerateRequest, options *opts.Options, col *plugi
 		return "[]byte"
 
 	case "date":
-		if driver == opts.SQLDriverPGXV5 {
+		if pgxWrappers {
 			return "pgtype.Date"
 		}
 		if notNull {
@@ -234,7 +237,7 @@ func postgresType(req *plugin.GenerateRequest, options *opts.Options, col *plugi
 		return "sql.NullTime"
 
 	case "pg_catalog.timestamp":
-		if driver == opts.SQLDriverPGXV5 {
+		if pgxWrappers {
 			return "pgtype.Timestamp"
 		}
 		if notNull {
@@ -246,7 +249,7 @@ func postgresType(req *plugin.GenerateRequest, options *opts.Options, col *plugi
 		return "sql.NullTime"
 
 	case "pg_catalog.timestamptz", "timestamptz":
-		if driver == opts.SQLDriverPGXV5 {
+		if pgxWrappers {
 			return "pgtype.Timestamptz"
 		}
 		if notNull {
@@ -270,7 +273,7 @@ func postgresType(req *plugin.GenerateRequest, options *opts.Options, col *plugi
 		return "sql.NullString"
 
 	case "uuid":
-		if driver == opts.SQLDriverPGXV5 {
+		if pgxWrappers {
 			return "pgtype.UUID"
 		}
 		if notNull {
@@ -341,6 +344,8 @@ func postgresType(req *plugin.GenerateRequest, options *opts.Options, col *plugi
 		return "sql.NullString"
 
 	case "interval", "pg_catalog.interval":
+		// pgx folds the months and days of an interval into a time.Duration,
+		// so native types keep pgtype.Interval too
 		if driver == opts.SQLDriverPGXV5 {
 			return "pgtype.Interval"
 		}
@@ -597,6 +602,24 @@ func postgresType(req *plugin.GenerateRequest, options *opts.Options, col *plugi
 				}
 			}
 		}
+
+		for _, d := range options.Domains {
+			if !d.Matches(rel, req.Catalog.DefaultSchema) {
+				continue
+			}
+			if options.EmitDomainTypes {
+				underlying := goInnerType(req, options, domainBaseColumn(col, d, true))
+				if isBasicGoType(underlying) {
+					if notNull {
+						return domainTypeName(req, options, d)
+					}
+					if emitPointersForNull {
+						return "*" + domainTypeName(req, options, d)
+					}
+				}
+			}
+			return goInnerType(req, options, domainBaseColumn(col, d, col.NotNull))
+		}
 	}
 
 	if debug.Active {
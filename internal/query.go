@@ -12,10 +12,13 @@ import (
 type QueryValue struct {
 	Emit        bool
 	EmitPointer bool
+	Shared      bool // The struct is defined by another query, see shareQueryStructs
 	Name        string
 	DBName      string // The name of the field in the database. Only set if Struct==nil.
 	Struct      *Struct
 	Typ         string
+	ArrayType   string     // See Field.ArrayType. Only set if Struct==nil.
+	Converter   *Converter // See Field.Converter. Only set if Struct==nil.
 	SQLDriver   opts.SQLDriver
 
 	// Column is kept so late in the generation process around to differentiate
@@ -27,6 +30,12 @@ func (v QueryValue) EmitStruct() bool {
 	return v.Emit
 }
 
+// DefineStruct reports whether the struct type is defined alongside this
+// query.
+func (v QueryValue) DefineStruct() bool {
+	return v.Emit && !v.Shared
+}
+
 func (v QueryValue) IsStruct() bool {
 	return v.Struct != nil
 }
@@ -61,6 +70,9 @@ func (v QueryValue) Pairs() []Argument {
 	if !v.EmitStruct() && v.IsStruct() {
 		var out []Argument
 		for _, f := range v.Struct.Fields {
+			if f.OptionalOf != "" {
+				continue
+			}
 			out = append(out, Argument{
 				Name: escape(toLowerCase(f.Name)),
 				Type: f.Type,
@@ -113,7 +125,7 @@ func (v QueryValue) UniqueFields() []Field {
 	fields := make([]Field, 0, len(v.Struct.Fields))
 
 	for _, field := range v.Struct.Fields {
-		if _, found := seen[field.Name]; found {
+		if _, found := seen[field.Name]; found || field.OptionalOf != "" {
 			continue
 		}
 		seen[field.Name] = struct{}{}
@@ -123,20 +135,108 @@ func (v QueryValue) UniqueFields() []Field {
 	return fields
 }
 
+// pqArray reports whether a value is bound and scanned with pq.Array when
+// using database/sql. Only PostgreSQL array columns are: other slices, such
+// as the Go types of overrides, are handled by the driver or the type itself.
+func pqArray(typ string, col *plugin.Column) bool {
+	return col != nil && col.IsArray && !col.IsSqlcSlice && strings.HasPrefix(typ, "[]") && typ != "[]byte"
+}
+
+// pqArrayElements are the element types pq.Array scans without them
+// implementing sql.Scanner.
+var pqArrayElements = map[string]struct{}{
+	"bool":    {},
+	"float32": {},
+	"float64": {},
+	"int32":   {},
+	"int64":   {},
+	"string":  {},
+	"[]byte":  {},
+}
+
+// checkPQArrays reports the columns scanned with pq.Array whose type is set
+// by a go_element_type override pq.Array can't scan into: it only scans
+// one-dimensional arrays, of the element types above or of types that
+// implement sql.Scanner, which types of other packages are assumed to.
+func checkPQArrays(req *plugin.GenerateRequest, options *opts.Options, qo queryOverrideIndex, structs []Struct, queries []Query) error {
+	if req.Settings.Engine != "postgresql" || parseDriver(options.SqlPackage).IsPGX() {
+		return nil
+	}
+	check := func(location, typ string, col *plugin.Column) error {
+		if !pqArray(typ, col) {
+			return nil
+		}
+		if override := typeOverride(req, options, qo, col); override == nil || !override.GoElement {
+			return nil
+		}
+		if col.ArrayDims > 1 {
+			return fmt.Errorf("column %s: pq.Array can't scan multi-dimensional arrays, use a go_type override or sql_package pgx/v5", location)
+		}
+		elem := strings.TrimPrefix(typ, "[]")
+		if _, ok := pqArrayElements[elem]; !ok && !strings.Contains(elem, ".") {
+			return fmt.Errorf("column %s: pq.Array can't scan elements of type %s, use one of bool, float32, float64, int32, int64, string, []byte or a type implementing sql.Scanner", location, elem)
+		}
+		return nil
+	}
+	for _, s := range structs {
+		for _, f := range s.Fields {
+			if f.ArrayType != "" || f.Converter != nil {
+				continue
+			}
+			if err := check(s.DBName+"."+f.DBName, f.Type, f.Column); err != nil {
+				return err
+			}
+		}
+	}
+	for _, q := range queries {
+		if !q.hasRetType() {
+			continue
+		}
+		if q.Ret.Struct == nil {
+			if q.Ret.ArrayType != "" || q.Ret.Converter != nil {
+				continue
+			}
+			if err := check(q.MethodName+"."+q.Ret.DBName, q.Ret.Typ, q.Ret.Column); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, f := range q.Ret.Struct.Fields {
+			if f.ArrayType != "" || f.Converter != nil {
+				continue
+			}
+			if err := check(q.MethodName+"."+f.DBName, f.Type, f.Column); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (v QueryValue) Params() string {
 	if v.isEmpty() {
 		return ""
 	}
 	var out []string
 	if v.Struct == nil {
-		if !v.Column.IsSqlcSlice && strings.HasPrefix(v.Typ, "[]") && v.Typ != "[]byte" && !v.SQLDriver.IsPGX() {
+		if v.Converter != nil && v.Converter.Encode != "" {
+			out = append(out, v.Bind(escape(v.Name)))
+		} else if v.ArrayType != "" {
+			out = append(out, v.ArrayType+"("+escape(v.Name)+")")
+		} else if pqArray(v.Typ, v.Column) && !v.SQLDriver.IsPGX() {
 			out = append(out, "pq.Array("+escape(v.Name)+")")
 		} else {
 			out = append(out, escape(v.Name))
 		}
 	} else {
 		for _, f := range v.Struct.Fields {
-			if !f.HasSqlcSlice() && strings.HasPrefix(f.Type, "[]") && f.Type != "[]byte" && !v.SQLDriver.IsPGX() {
+			if f.OptionalOf != "" {
+				out = append(out, escape(v.VariableForField(Field{Name: f.OptionalOf}))+".IsSet()")
+			} else if f.HasEncoder() {
+				out = append(out, f.Bind(escape(v.VariableForField(f))))
+			} else if f.ArrayType != "" {
+				out = append(out, f.ArrayType+"("+escape(v.VariableForField(f))+")")
+			} else if pqArray(f.Type, f.Column) && !v.SQLDriver.IsPGX() {
 				out = append(out, "pq.Array("+escape(v.VariableForField(f))+")")
 			} else {
 				out = append(out, escape(v.VariableForField(f)))
@@ -193,7 +293,11 @@ func (v QueryValue) HasSqlcSlices() bool {
 func (v QueryValue) Scan() string {
 	var out []string
 	if v.Struct == nil {
-		if strings.HasPrefix(v.Typ, "[]") && v.Typ != "[]byte" && !v.SQLDriver.IsPGX() {
+		if v.Converter != nil && v.Converter.Decode != "" {
+			out = append(out, "&"+v.Name+"Native")
+		} else if v.ArrayType != "" {
+			out = append(out, "(*"+v.ArrayType+")(&"+v.Name+")")
+		} else if pqArray(v.Typ, v.Column) && !v.SQLDriver.IsPGX() {
 			out = append(out, "pq.Array(&"+v.Name+")")
 		} else {
 			out = append(out, "&"+v.Name)
@@ -203,17 +307,29 @@ func (v QueryValue) Scan() string {
 
 			// append any embedded fields
 			if len(f.EmbedFields) > 0 {
+				prefix := v.Name + "." + f.Name
+				if f.Nested || f.EmbedNullable {
+					prefix = f.scanVar()
+				}
 				for _, embed := range f.EmbedFields {
-					if strings.HasPrefix(embed.Type, "[]") && embed.Type != "[]byte" && !v.SQLDriver.IsPGX() {
-						out = append(out, "pq.Array(&"+v.Name+"."+f.Name+"."+embed.Name+")")
+					if embed.hasDecoder() {
+						out = append(out, "&"+f.nativeVar(&embed))
+					} else if embed.ArrayType != "" {
+						out = append(out, "(*"+embed.ArrayType+")(&"+prefix+"."+embed.Name+")")
+					} else if pqArray(embed.Type, embed.Column) && !v.SQLDriver.IsPGX() {
+						out = append(out, "pq.Array(&"+prefix+"."+embed.Name+")")
 					} else {
-						out = append(out, "&"+v.Name+"."+f.Name+"."+embed.Name)
+						out = append(out, "&"+prefix+"."+embed.Name)
 					}
 				}
 				continue
 			}
 
-			if strings.HasPrefix(f.Type, "[]") && f.Type != "[]byte" && !v.SQLDriver.IsPGX() {
+			if f.hasDecoder() {
+				out = append(out, "&"+f.nativeVar(nil))
+			} else if f.ArrayType != "" {
+				out = append(out, "(*"+f.ArrayType+")(&"+v.Name+"."+f.Name+")")
+			} else if pqArray(f.Type, f.Column) && !v.SQLDriver.IsPGX() {
 				out = append(out, "pq.Array(&"+v.Name+"."+f.Name+")")
 			} else {
 				out = append(out, "&"+v.Name+"."+f.Name)
@@ -227,6 +343,153 @@ func (v QueryValue) Scan() string {
 	return "\n" + strings.Join(out, ",\n")
 }
 
+// ScanVars declares the variables that nested and nullable embedded tables
+// are scanned into, see ScanAssign, and the native values of fields with
+// decoders, see ScanDecode.
+func (v QueryValue) ScanVars() string {
+	if v.Struct == nil {
+		if v.Converter != nil && v.Converter.Decode != "" {
+			return "var " + v.Name + "Native " + v.Converter.Native
+		}
+		return ""
+	}
+	var out []string
+	for _, f := range v.Struct.Fields {
+		if f.hasDecoder() {
+			out = append(out, "var "+f.nativeVar(nil)+" "+f.Converter.Native)
+		}
+		for _, embed := range f.EmbedFields {
+			if embed.hasDecoder() {
+				out = append(out, "var "+f.nativeVar(&embed)+" "+embed.Converter.Native)
+			}
+		}
+		if !f.EmbedNullable {
+			if f.Nested {
+				out = append(out, "var "+f.scanVar()+" "+f.ElemType())
+			}
+			continue
+		}
+		var fields []string
+		for _, embed := range f.EmbedFields {
+			typ := embed.Type
+			if embed.scannedAsPointer() {
+				typ = "*" + typ
+			}
+			fields = append(fields, embed.Name+" "+typ)
+		}
+		out = append(out, "var "+f.scanVar()+" struct {\n"+strings.Join(fields, "\n")+"\n}")
+	}
+	return strings.Join(out, "\n")
+}
+
+// ScanDecode converts the native values scanned for fields with decoders.
+// onError is run when a conversion fails, when empty the error is left in err
+// and the remaining conversions are skipped.
+func (v QueryValue) ScanDecode(onError string) string {
+	if v.Struct == nil {
+		if v.Converter != nil && v.Converter.Decode != "" {
+			return decodeStmt(v.Name, v.Converter, v.Name+"Native", onError)
+		}
+		return ""
+	}
+	var out []string
+	for _, f := range v.Struct.Fields {
+		if f.hasDecoder() {
+			out = append(out, decodeStmt(v.Name+"."+f.Name, f.Converter, f.nativeVar(nil), onError))
+		}
+		prefix := v.Name + "." + f.Name
+		if f.Nested || f.EmbedNullable {
+			prefix = f.scanVar()
+		}
+		for _, embed := range f.EmbedFields {
+			if embed.hasDecoder() {
+				out = append(out, decodeStmt(prefix+"."+embed.Name, embed.Converter, f.nativeVar(&embed), onError))
+			}
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// Bind returns the expression binding the value as a query parameter.
+func (v QueryValue) Bind(expr string) string {
+	return Field{Converter: v.Converter}.Bind(expr)
+}
+
+// converters returns the converters of the value and its fields.
+func (v QueryValue) converters() []*Converter {
+	if v.Struct == nil {
+		if v.Converter != nil {
+			return []*Converter{v.Converter}
+		}
+		return nil
+	}
+	var out []*Converter
+	for _, f := range v.Struct.Fields {
+		if f.Converter != nil {
+			out = append(out, f.Converter)
+		}
+		for _, embed := range f.EmbedFields {
+			if embed.Converter != nil {
+				out = append(out, embed.Converter)
+			}
+		}
+	}
+	return out
+}
+
+// ScanAssign moves the embedded tables declared by ScanVars into the
+// result once a row has been scanned. Nested tables are appended to the
+// previous result when the row belongs to the same parent.
+func (v QueryValue) ScanAssign() string {
+	if v.Struct == nil {
+		return ""
+	}
+	var out []string
+	for _, f := range v.Struct.Fields {
+		value := f.embedValue()
+		switch {
+		case f.Nested:
+			appendChild := "items[n-1]." + f.Name + " = append(items[n-1]." + f.Name + ", " + value + ")"
+			setChild := v.Name + "." + f.Name + " = []" + f.ElemType() + "{" + f.scanVar() + "}"
+			if f.EmbedNullable {
+				// The element type is elided from the literal of the slice
+				setChild = v.Name + "." + f.Name + " = []" + f.ElemType() + "{" + f.embedFieldValues() + "}"
+				appendChild = "if " + f.embedPresent() + " {\n" + appendChild + "\n}"
+				setChild = "if " + f.embedPresent() + " {\n" + setChild + "\n}"
+			}
+			key := v.NestKey()
+			out = append(out, "if n := len(items); n > 0 && items[n-1]."+key+" == "+v.Name+"."+key+" {\n"+
+				appendChild+"\ncontinue\n}\n"+setChild)
+		case f.EmbedNullable:
+			out = append(out, "if "+f.embedPresent()+" {\n"+v.Name+"."+f.Name+" = &"+value+"\n}")
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// NestedField returns the field that rows are aggregated into, or nil.
+func (v QueryValue) NestedField() *Field {
+	if v.Struct == nil {
+		return nil
+	}
+	for i := range v.Struct.Fields {
+		if v.Struct.Fields[i].Nested {
+			return &v.Struct.Fields[i]
+		}
+	}
+	return nil
+}
+
+// NestKey returns the path of the field that identifies the parent of
+// consecutive rows when they are aggregated, see nestEmbed.
+func (v QueryValue) NestKey() string {
+	f := v.Struct.Fields[0]
+	if len(f.EmbedFields) > 0 {
+		return f.Name + "." + parentKey(f).Name
+	}
+	return f.Name
+}
+
 // Deprecated: This method does not respect the Emit field set on the
 // QueryValue. It's used by the go-sql-driver-mysql/copyfromCopy.tmpl and should
 // not be used other places.
@@ -237,9 +500,10 @@ func (v QueryValue) CopyFromMySQLFields() []Field {
 	}
 	return []Field{
 		{
-			Name:   v.Name,
-			DBName: v.DBName,
-			Type:   v.Typ,
+			Name:      v.Name,
+			DBName:    v.DBName,
+			Type:      v.Typ,
+			Converter: v.Converter,
 		},
 	}
 }
@@ -263,10 +527,14 @@ type Query struct {
 	ConstantName string
 	SQL          string
 	SourceName   string
+	Fingerprint  string // See queryFingerprint.
+	SQLComment   string // Prepended to SQL when emit_sql_commenter is set.
 	Ret          QueryValue
 	Arg          QueryValue
 	// Used for :copyfrom
 	Table *plugin.Identifier
+
+	EmitEmptySlices bool
 }
 
 func (q Query) hasRetType() bool {
@@ -275,6 +543,44 @@ func (q Query) hasRetType() bool {
 	return scanned && !q.Ret.isEmpty()
 }
 
+// ParamDescriptors returns the database name and Go type of each query
+// parameter, in the order they are passed to the database.
+func (q Query) ParamDescriptors() []Argument {
+	if q.Arg.isEmpty() {
+		return nil
+	}
+	if q.Arg.Struct == nil {
+		return []Argument{{Name: q.Arg.DBName, Type: q.Arg.Typ}}
+	}
+	out := make([]Argument, len(q.Arg.Struct.Fields))
+	for i, f := range q.Arg.Struct.Fields {
+		out[i] = Argument{Name: f.DBName, Type: f.Type}
+	}
+	return out
+}
+
+// ResultColumns returns the names of the columns scanned by the query, with
+// embedded tables expanded into their columns.
+func (q Query) ResultColumns() []string {
+	if !q.hasRetType() {
+		return nil
+	}
+	if q.Ret.Struct == nil {
+		return []string{q.Ret.DBName}
+	}
+	var out []string
+	for _, f := range q.Ret.Struct.Fields {
+		if len(f.EmbedFields) == 0 {
+			out = append(out, f.DBName)
+			continue
+		}
+		for _, embed := range f.EmbedFields {
+			out = append(out, embed.DBName)
+		}
+	}
+	return out
+}
+
 func (q Query) TableIdentifierAsGoSlice() string {
 	escapedNames := make([]string, 0, 3)
 	for _, p := range []string{q.Table.Catalog, q.Table.Schema, q.Table.Name} {
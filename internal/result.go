@@ -20,36 +20,50 @@ func buildEnums(req *plugin.GenerateRequest, options *opts.Options) []Enum {
 			continue
 		}
 		for _, enum := range schema.Enums {
-			var enumName string
+			var enumName, dbName string
 			if schema.Name == req.Catalog.DefaultSchema {
 				enumName = enum.Name
+				dbName = enum.Name
 			} else {
 				enumName = schema.Name + "_" + enum.Name
+				dbName = schema.Name + "." + enum.Name
+			}
+			if enumOverride(options, dbName, schema.Name+"."+enum.Name) != nil {
+				continue
 			}
 
 			e := Enum{
 				Name:      StructName(enumName, options),
+				DBName:    dbName,
 				Comment:   enum.Comment,
 				NameTags:  map[string]string{},
 				ValidTags: map[string]string{},
 			}
+			if _, ok := options.MySQLSets[enum.Name]; ok {
+				e.SetName = e.Name + "Set"
+			}
 			if options.EmitJsonTags {
 				e.NameTags["json"] = JSONTagName(enumName, options)
 				e.ValidTags["json"] = JSONTagName("valid", options)
 			}
 
-			seen := make(map[string]struct{}, len(enum.Vals))
-			for i, v := range enum.Vals {
+			// Values without a name of their own or sharing one are
+			// reported by validate, see enum_value_rename
+			renames := options.EnumValueRename[dbName]
+			for _, v := range enum.Vals {
 				value := EnumReplace(v)
-				if _, found := seen[value]; found || value == "" {
-					value = fmt.Sprintf("value_%d", i)
+				if rename, ok := renames[v]; ok {
+					value = rename
+				}
+				var name string
+				if value != "" {
+					name = enumConstantName(enumName, value, options)
 				}
 				e.Constants = append(e.Constants, Constant{
-					Name:  StructName(enumName+"_"+value, options),
+					Name:  name,
 					Value: v,
 					Type:  e.Name,
 				})
-				seen[value] = struct{}{}
 			}
 			enums = append(enums, e)
 		}
@@ -60,29 +74,60 @@ func buildEnums(req *plugin.GenerateRequest, options *opts.Options) []Enum {
 	return enums
 }
 
-func buildStructs(req *plugin.GenerateRequest, options *opts.Options) []Struct {
+func buildDomains(req *plugin.GenerateRequest, options *opts.Options) []Domain {
+	if !options.EmitDomainTypes || req.Settings.Engine != "postgresql" {
+		return nil
+	}
+	var domains []Domain
+	for _, d := range options.Domains {
+		typ := goInnerType(req, options, &plugin.Column{
+			Type:    d.BaseType(),
+			NotNull: true,
+		})
+		if !isBasicGoType(typ) {
+			continue
+		}
+		domains = append(domains, Domain{
+			Name: domainTypeName(req, options, d),
+			Type: typ,
+		})
+	}
+	if len(domains) > 0 {
+		sort.Slice(domains, func(i, j int) bool { return domains[i].Name < domains[j].Name })
+	}
+	return domains
+}
+
+// modelName returns the name of the struct generated for a table.
+func modelName(req *plugin.GenerateRequest, options *opts.Options, schema, table string) string {
+	name := table
+	if schema != req.Catalog.DefaultSchema {
+		name = schema + "_" + table
+	}
+	if !options.EmitExactTableNames {
+		name = inflection.Singular(inflection.SingularParams{
+			Name:       name,
+			Exclusions: options.InflectionExcludeTableNames,
+		})
+	}
+	return StructName(name, options)
+}
+
+func buildStructs(req *plugin.GenerateRequest, options *opts.Options, ids *typedIDIndex) []Struct {
 	var structs []Struct
 	for _, schema := range req.Catalog.Schemas {
 		if schema.Name == "pg_catalog" || schema.Name == "information_schema" {
 			continue
 		}
 		for _, table := range schema.Tables {
-			var tableName string
-			if schema.Name == req.Catalog.DefaultSchema {
-				tableName = table.Rel.Name
-			} else {
-				tableName = schema.Name + "_" + table.Rel.Name
-			}
-			structName := tableName
-			if !options.EmitExactTableNames {
-				structName = inflection.Singular(inflection.SingularParams{
-					Name:       structName,
-					Exclusions: options.InflectionExcludeTableNames,
-				})
+			dbName := table.Rel.Name
+			if schema.Name != req.Catalog.DefaultSchema {
+				dbName = schema.Name + "." + table.Rel.Name
 			}
 			s := Struct{
 				Table:   &plugin.Identifier{Schema: schema.Name, Name: table.Rel.Name},
-				Name:    StructName(structName, options),
+				Name:    modelName(req, options, schema.Name, table.Rel.Name),
+				DBName:  dbName,
 				Comment: table.Comment,
 			}
 			for _, column := range table.Columns {
@@ -93,12 +138,16 @@ func buildStructs(req *plugin.GenerateRequest, options *opts.Options) []Struct {
 				if options.EmitJsonTags {
 					tags["json"] = JSONTagName(column.Name, options)
 				}
-				addExtraGoStructTags(tags, req, options, column)
+				addExtraGoStructTags(tags, req, options, nil, column)
 				s.Fields = append(s.Fields, Field{
-					Name:    StructName(column.Name, options),
-					Type:    goType(req, options, column),
-					Tags:    tags,
-					Comment: column.Comment,
+					Name:      StructName(column.Name, options),
+					DBName:    column.Name,
+					Type:      goType(req, options, ids, nil, column),
+					Tags:      tags,
+					Comment:   column.Comment,
+					Column:    column,
+					ArrayType: goEnumArrayType(req, options, ids, nil, column),
+					Converter: goTypeConverter(req, options, ids, nil, column),
 				})
 			}
 			structs = append(structs, s)
@@ -183,8 +232,9 @@ func argName(name string) string {
 	return out
 }
 
-func buildQueries(req *plugin.GenerateRequest, options *opts.Options, structs []Struct) ([]Query, error) {
+func buildQueries(req *plugin.GenerateRequest, options *opts.Options, ids *typedIDIndex, qo queryOverrideIndex, structs []Struct) ([]Query, error) {
 	qs := make([]Query, 0, len(req.Queries))
+	named := map[*Struct]struct{}{}
 	for _, query := range req.Queries {
 		if query.Name == "" {
 			continue
@@ -200,7 +250,13 @@ func buildQueries(req *plugin.GenerateRequest, options *opts.Options, structs []
 			constantName = sdk.LowerTitle(query.Name)
 		}
 
-		comments := query.Comments
+		annotations, err := parseQueryAnnotations(query.Name, query.Comments, options)
+		if err != nil {
+			return nil, err
+		}
+		qopts := annotations.Options
+
+		comments := annotations.Comments
 		if options.EmitSqlAsComment {
 			if len(comments) == 0 {
 				comments = append(comments, query.Name)
@@ -215,6 +271,12 @@ func buildQueries(req *plugin.GenerateRequest, options *opts.Options, structs []
 				return nil, err
 			}
 		}
+		if annotations.Deprecated != "" {
+			if len(comments) > 0 {
+				comments = append(comments, "")
+			}
+			comments = append(comments, " Deprecated: "+annotations.Deprecated)
+		}
 
 		gq := Query{
 			Cmd:          query.Cmd,
@@ -225,17 +287,35 @@ func buildQueries(req *plugin.GenerateRequest, options *opts.Options, structs []
 			SQL:          query.Text,
 			Comments:     comments,
 			Table:        query.InsertIntoTable,
+
+			EmitEmptySlices: qopts.EmitEmptySlices,
+		}
+		if options.EmitQueryFingerprints || options.EmitSqlCommenter {
+			gq.Fingerprint = queryFingerprint(query.Text)
+		}
+		if options.EmitSqlCommenter {
+			gq.SQLComment = sqlComment(
+				[2]string{"fingerprint", gq.Fingerprint},
+				[2]string{"name", query.Name},
+			)
 		}
 		sqlpkg := parseDriver(options.SqlPackage)
 
 		qpl := int(*options.QueryParameterLimit)
 
-		if len(query.Params) == 1 && qpl != 0 {
+		if annotations.ParamsStruct != "" && len(query.Params) == 0 {
+			return nil, fmt.Errorf("query %s: annotation %sparams-struct: query has no parameters", query.Name, annotationPrefix)
+		}
+
+		forceStruct := annotations.ParamsStruct != "" || annotations.ModelParams
+		if len(query.Params) == 1 && qpl != 0 && !forceStruct {
 			p := query.Params[0]
 			gq.Arg = QueryValue{
 				Name:      escape(paramName(p)),
 				DBName:    p.Column.GetName(),
-				Typ:       goType(req, options, p.Column),
+				Typ:       goType(req, qopts, ids, qo, p.Column),
+				ArrayType: goEnumArrayType(req, qopts, ids, qo, p.Column),
+				Converter: goTypeConverter(req, qopts, ids, qo, p.Column),
 				SQLDriver: sqlpkg,
 				Column:    p.Column,
 			}
@@ -247,23 +327,45 @@ func buildQueries(req *plugin.GenerateRequest, options *opts.Options, structs []
 					Column: p.Column,
 				})
 			}
-			s, err := columnsToStruct(req, options, gq.MethodName+"Params", cols, false)
+			paramsName := gq.MethodName + "Params"
+			if annotations.ParamsStruct != "" {
+				paramsName = annotations.ParamsStruct
+			}
+			s, err := columnsToStruct(req, qopts, ids, qo, paramsName, cols, false)
 			if err != nil {
 				return nil, err
 			}
+			if annotations.ParamsStruct != "" {
+				named[s] = struct{}{}
+			}
 			gq.Arg = QueryValue{
 				Emit:        true,
 				Name:        "arg",
 				Struct:      s,
 				SQLDriver:   sqlpkg,
-				EmitPointer: options.EmitParamsStructPointers,
+				EmitPointer: qopts.EmitParamsStructPointers,
 			}
 
 			// if query params is 2, and query params limit is 4 AND this is a copyfrom, we still want to emit the query's model
 			// otherwise we end up with a copyfrom using a struct without the struct definition
-			if len(query.Params) <= qpl && query.Cmd != ":copyfrom" {
+			if len(query.Params) <= qpl && query.Cmd != ":copyfrom" && !forceStruct {
 				gq.Arg.Emit = false
 			}
+
+			if options.EmitOptionalParams && !usesBatch([]Query{gq}) && query.Cmd != metadata.CmdCopyFrom {
+				optionalParams(req, qopts, ids, qo, s)
+			}
+
+			if gq.Arg.Emit && (options.EmitModelParams || annotations.ModelParams) {
+				if model := matchingModel(req, s.Fields, structs, true); model != nil {
+					// Keep the fields in parameter order, the model
+					// has the same field names and types.
+					gq.Arg.Struct = &Struct{Table: model.Table, Name: model.Name, Fields: s.Fields}
+					gq.Arg.Shared = true
+				} else if annotations.ModelParams {
+					return nil, fmt.Errorf("query %s: annotation %smodel-params: parameters do not match the columns of a table", query.Name, annotationPrefix)
+				}
+			}
 		}
 
 		if len(query.Columns) == 1 && query.Columns[0].EmbedTable == nil {
@@ -273,30 +375,30 @@ func buildQueries(req *plugin.GenerateRequest, options *opts.Options, structs []
 			gq.Ret = QueryValue{
 				Name:      escape(name),
 				DBName:    name,
-				Typ:       goType(req, options, c),
+				Typ:       goType(req, qopts, ids, qo, c),
+				ArrayType: goEnumArrayType(req, qopts, ids, qo, c),
+				Converter: goTypeConverter(req, qopts, ids, qo, c),
 				SQLDriver: sqlpkg,
+				Column:    c,
 			}
 		} else if putOutColumns(query) {
 			var gs *Struct
 			var emit bool
 
-			for _, s := range structs {
-				if len(s.Fields) != len(query.Columns) {
-					continue
-				}
-				same := true
-				for i, f := range s.Fields {
-					c := query.Columns[i]
-					sameName := f.Name == StructName(columnName(c, i), options)
-					sameType := f.Type == goType(req, options, c)
-					sameTable := sdk.SameTableName(c.Table, s.Table, req.Catalog.DefaultSchema)
-					if !sameName || !sameType || !sameTable {
-						same = false
+			// A struct named with an annotation is always generated, even
+			// if the columns match a model.
+			if annotations.ResultStruct == "" {
+				fields := make([]Field, len(query.Columns))
+				for i, c := range query.Columns {
+					fields[i] = Field{
+						Name:   StructName(columnName(c, i), options),
+						Type:   goType(req, qopts, ids, qo, c),
+						Column: c,
 					}
 				}
-				if same {
+				if model := matchingModel(req, fields, structs, false); model != nil {
+					s := *model
 					gs = &s
-					break
 				}
 			}
 
@@ -309,28 +411,414 @@ func buildQueries(req *plugin.GenerateRequest, options *opts.Options, structs []
 						embed:  newGoEmbed(c.EmbedTable, structs, req.Catalog.DefaultSchema),
 					})
 				}
+				rowName := gq.MethodName + "Row"
+				if annotations.ResultStruct != "" {
+					rowName = annotations.ResultStruct
+				}
 				var err error
-				gs, err = columnsToStruct(req, options, gq.MethodName+"Row", columns, true)
+				gs, err = columnsToStruct(req, qopts, ids, qo, rowName, columns, true)
 				if err != nil {
 					return nil, err
 				}
 				emit = true
+				if annotations.ResultStruct != "" {
+					named[gs] = struct{}{}
+				}
 			}
 			gq.Ret = QueryValue{
 				Emit:        emit,
 				Name:        "i",
 				Struct:      gs,
 				SQLDriver:   sqlpkg,
-				EmitPointer: options.EmitResultStructPointers,
+				EmitPointer: qopts.EmitResultStructPointers,
 			}
 		}
 
+		if annotations.Nest != "" {
+			if err := nestEmbed(&gq, annotations.Nest, options); err != nil {
+				return nil, fmt.Errorf("query %s: annotation %snest: %w", query.Name, annotationPrefix, err)
+			}
+		}
+
+		if len(annotations.NullEmbeds) > 0 {
+			if err := nullableEmbeds(&gq, annotations.NullEmbeds); err != nil {
+				return nil, fmt.Errorf("query %s: annotation %snullable-embed: %w", query.Name, annotationPrefix, err)
+			}
+		}
+
+		if err := checkConverters(gq.Arg); err != nil {
+			return nil, fmt.Errorf("query %s: %w", query.Name, err)
+		}
+		if err := checkConverters(gq.Ret); err != nil {
+			return nil, fmt.Errorf("query %s: %w", query.Name, err)
+		}
+
+		if annotations.ResultStruct != "" && !gq.Ret.IsStruct() {
+			return nil, fmt.Errorf("query %s: annotation %sresult-struct: query does not return a struct", query.Name, annotationPrefix)
+		}
+
 		qs = append(qs, gq)
 	}
 	sort.Slice(qs, func(i, j int) bool { return qs[i].MethodName < qs[j].MethodName })
+	if err := shareQueryStructs(qs, named, options.DedupeQueryStructs); err != nil {
+		return nil, err
+	}
 	return qs, nil
 }
 
+// queryOverrideIndex holds the query scoped overrides by the parameter or
+// result column they match, see buildQueryOverrides.
+type queryOverrideIndex map[*plugin.Column]*opts.Override
+
+// buildQueryOverrides returns the query scoped overrides matching the
+// parameters and result columns of the queries. The first matching override
+// of a query wins.
+func buildQueryOverrides(req *plugin.GenerateRequest, options *opts.Options) queryOverrideIndex {
+	overrides := queryOverrideIndex{}
+	for i := range options.Overrides {
+		o := &options.Overrides[i]
+		if o.QueryName == nil {
+			continue
+		}
+		for _, query := range req.Queries {
+			for _, p := range query.Params {
+				name := p.Column.GetName()
+				if name == "" {
+					name = fmt.Sprintf("dollar_%d", p.Number)
+				}
+				if _, ok := overrides[p.Column]; !ok && p.Column != nil && o.MatchesParam(query.Name, name) {
+					overrides[p.Column] = o
+				}
+			}
+			for j, c := range query.Columns {
+				if _, ok := overrides[c]; !ok && c.EmbedTable == nil && o.MatchesResult(query.Name, columnName(c, j)) {
+					overrides[c] = o
+				}
+			}
+		}
+	}
+	return overrides
+}
+
+// shareQueryStructs makes queries whose params or result structs have the
+// same name share a single definition. With dedupe set, structurally
+// identical structs are shared as well, using the name of the first query
+// that defines them unless one of them was named with an annotation.
+func shareQueryStructs(queries []Query, named map[*Struct]struct{}, dedupe bool) error {
+	for _, isArg := range []bool{true, false} {
+		var values []*QueryValue
+		for i := range queries {
+			v := &queries[i].Ret
+			if isArg {
+				// Batch params structs are always defined by their query.
+				if usesBatch(queries[i : i+1]) {
+					continue
+				}
+				v = &queries[i].Arg
+			}
+			if v.Emit && v.Struct != nil && !v.Shared {
+				values = append(values, v)
+			}
+		}
+
+		// Named structs go first, so that unnamed structs join them.
+		var defined []*Struct
+		byName := map[string]*Struct{}
+		for _, v := range values {
+			if _, ok := named[v.Struct]; !ok {
+				continue
+			}
+			if s, ok := byName[v.Struct.Name]; ok {
+				if !sameStructShape(s, v.Struct) {
+					return fmt.Errorf("struct %s is used for queries with different columns", s.Name)
+				}
+				v.Struct, v.Shared = s, true
+				continue
+			}
+			byName[v.Struct.Name] = v.Struct
+			defined = append(defined, v.Struct)
+		}
+		if !dedupe {
+			continue
+		}
+		for _, v := range values {
+			if _, ok := named[v.Struct]; ok {
+				continue
+			}
+			for _, s := range defined {
+				if sameStructShape(s, v.Struct) {
+					v.Struct, v.Shared = s, true
+					break
+				}
+			}
+			if !v.Shared {
+				defined = append(defined, v.Struct)
+			}
+		}
+	}
+	return nil
+}
+
+// nestEmbed turns the field of an embedded table into a slice, so that
+// consecutive rows sharing the value of the first column are aggregated into
+// a single result.
+func nestEmbed(gq *Query, table string, options *opts.Options) error {
+	if gq.Cmd != metadata.CmdMany {
+		return fmt.Errorf("only supported by %s queries", metadata.CmdMany)
+	}
+	if !gq.Ret.DefineStruct() {
+		return fmt.Errorf("query does not return a struct with embedded tables")
+	}
+	fields := gq.Ret.Struct.Fields
+	for i, f := range fields {
+		if f.Column == nil || f.Column.EmbedTable == nil || f.Column.EmbedTable.Name != table {
+			continue
+		}
+		if i == 0 {
+			return fmt.Errorf("the first column identifies the parent and can't be nested")
+		}
+		if key := parentKey(fields[0]); !isComparableGoType(key.Type) {
+			return fmt.Errorf("the first column %s identifies the parent and its type %s can't be compared", key.DBName, key.Type)
+		}
+		f.Name = StructName(table, options)
+		f.Type = "[]" + f.Type
+		f.Nested = true
+		if _, ok := f.Tags["db"]; ok {
+			f.Tags["db"] = table
+		}
+		if _, ok := f.Tags["json"]; ok {
+			f.Tags["json"] = JSONTagName(table, options)
+		}
+		for j, other := range fields {
+			if j != i && other.Name == f.Name {
+				return fmt.Errorf("field %s already exists", f.Name)
+			}
+		}
+		fields[i] = f
+		return nil
+	}
+	return fmt.Errorf("no sqlc.embed(%s) column", table)
+}
+
+// parentKey returns the field identifying the parent of nested rows, the
+// first column of the result or of the table embedded first.
+func parentKey(f Field) Field {
+	if len(f.EmbedFields) > 0 {
+		return f.EmbedFields[0]
+	}
+	return f
+}
+
+// isComparableGoType reports whether values of a Go type can be compared
+// with ==. Only the types the generator maps columns to are recognized.
+func isComparableGoType(typ string) bool {
+	typ = strings.TrimPrefix(typ, "*")
+	for _, prefix := range []string{"[]", "map[", "func("} {
+		if strings.HasPrefix(typ, prefix) {
+			return false
+		}
+	}
+	switch typ {
+	case "json.RawMessage", "pqtype.NullRawMessage", "interface{}", "any":
+		return false
+	}
+	return true
+}
+
+// nullableEmbeds marks the fields of embedded tables that may be missing
+// from a row, e.g. because of a LEFT JOIN. They are generated as pointers to
+// their model, or left out of a nested slice, when all of the table's NOT NULL
+// columns are NULL.
+func nullableEmbeds(gq *Query, tables []string) error {
+	if !gq.Ret.DefineStruct() {
+		return fmt.Errorf("query does not return a struct with embedded tables")
+	}
+	fields := gq.Ret.Struct.Fields
+	for _, table := range tables {
+		found := false
+		for i, f := range fields {
+			if f.Column == nil || f.Column.EmbedTable == nil || f.Column.EmbedTable.Name != table {
+				continue
+			}
+			found = true
+			if i == 0 && gq.Ret.NestedField() != nil {
+				return fmt.Errorf("the first column identifies the parent of nested rows and can't be nullable")
+			}
+			if f.embedPresent() == "" {
+				return fmt.Errorf("table %s has no NOT NULL column to detect a missing row", table)
+			}
+			if !f.Nested {
+				f.Type = "*" + f.Type
+			}
+			f.EmbedNullable = true
+			fields[i] = f
+		}
+		if !found {
+			return fmt.Errorf("no sqlc.embed(%s) column", table)
+		}
+	}
+	return nil
+}
+
+// optionalParams turns a parameter x that comes with a boolean set_x
+// parameter into an Optional field. This supports partial updates written
+// as SET x = CASE WHEN @set_x THEN @x ELSE x END, where set_x is bound
+// from whether the Optional is set.
+func optionalParams(req *plugin.GenerateRequest, options *opts.Options, ids *typedIDIndex, qo queryOverrideIndex, s *Struct) {
+	for i, flag := range s.Fields {
+		name, ok := strings.CutPrefix(flag.DBName, "set_")
+		typ := flag.Column.GetType().GetName()
+		if !ok || typ != "bool" && typ != "boolean" {
+			continue
+		}
+		for j, f := range s.Fields {
+			if f.DBName != name || f.Column == nil || f.Column.IsArray || f.HasSqlcSlice() || f.Converter != nil {
+				continue
+			}
+			if !strings.HasPrefix(f.Type, "Optional[") {
+				elem := f.Type
+				if !f.Column.NotNull && typeOverride(req, options, qo, f.Column) == nil {
+					// The Optional holds NULL itself, so it wraps the type
+					// of the column when it is NOT NULL.
+					elem = goType(req, options, ids, qo, &plugin.Column{
+						Name:     f.Column.Name,
+						Type:     f.Column.Type,
+						Table:    f.Column.Table,
+						NotNull:  true,
+						Unsigned: f.Column.Unsigned,
+					})
+				}
+				s.Fields[j].Type = "Optional[" + elem + "]"
+			}
+			s.Fields[i].OptionalOf = s.Fields[j].Name
+		}
+	}
+}
+
+// matchingModel returns the table model whose fields are exactly the given
+// fields: the same names and types, holding columns of the model's table. The
+// fields must be in the order of the model unless anyOrder is set. It returns
+// nil if there is no such model.
+func matchingModel(req *plugin.GenerateRequest, fields []Field, structs []Struct, anyOrder bool) *Struct {
+	for i := range structs {
+		s := &structs[i]
+		if s.Table == nil || len(s.Fields) != len(fields) {
+			continue
+		}
+		modelFields := map[string]string{}
+		for _, f := range s.Fields {
+			modelFields[f.Name] = f.Type
+		}
+		same := true
+		for j, f := range fields {
+			typ, ok := modelFields[f.Name]
+			delete(modelFields, f.Name)
+			inOrder := anyOrder || s.Fields[j].Name == f.Name
+			sameTable := sdk.SameTableName(f.Column.GetTable(), s.Table, req.Catalog.DefaultSchema)
+			if !ok || !inOrder || typ != f.Type || !sameTable || f.HasSqlcSlice() {
+				same = false
+				break
+			}
+		}
+		if same {
+			return s
+		}
+	}
+	return nil
+}
+
+// addModelConversions records the conversions between the params and result
+// structs defined by queries and the table models. Fields are matched by
+// table and column, so a struct converts to a model when it holds every
+// column of the model, and from a model when all of its columns belong to
+// the model.
+func addModelConversions(req *plugin.GenerateRequest, structs []Struct, queries []Query) {
+	seen := map[*Struct]struct{}{}
+	for _, q := range queries {
+		for _, v := range []QueryValue{q.Arg, q.Ret} {
+			if !v.DefineStruct() {
+				continue
+			}
+			if _, ok := seen[v.Struct]; ok {
+				continue
+			}
+			seen[v.Struct] = struct{}{}
+
+			for _, model := range structs {
+				if model.Table == nil {
+					continue
+				}
+				// The struct field holding each of the model's columns
+				columns := map[string]string{}
+				fromModel := true
+				seenFields := map[string]struct{}{}
+				var from []FieldPair
+				for _, f := range v.Struct.Fields {
+					if _, ok := seenFields[f.Name]; ok {
+						continue
+					}
+					seenFields[f.Name] = struct{}{}
+					mf, ok := modelField(req, model, f)
+					if !ok {
+						fromModel = false
+						continue
+					}
+					if _, ok := columns[mf]; !ok {
+						columns[mf] = f.Name
+					}
+					from = append(from, FieldPair{Field: f.Name, ModelField: mf})
+				}
+				if fromModel && len(from) > 0 {
+					v.Struct.FromModels = append(v.Struct.FromModels, ModelConversion{Model: model.Name, Fields: from})
+				}
+				if len(columns) == len(model.Fields) {
+					var to []FieldPair
+					for _, mf := range model.Fields {
+						to = append(to, FieldPair{Field: columns[mf.Name], ModelField: mf.Name})
+					}
+					v.Struct.ToModels = append(v.Struct.ToModels, ModelConversion{Model: model.Name, Fields: to})
+				}
+			}
+		}
+	}
+}
+
+// modelField returns the name of the model field holding the same table
+// column as f, provided both have the same type.
+func modelField(req *plugin.GenerateRequest, model Struct, f Field) (string, bool) {
+	if f.Column == nil || !sdk.SameTableName(f.Column.Table, model.Table, req.Catalog.DefaultSchema) {
+		return "", false
+	}
+	name := f.Column.Name
+	if f.Column.OriginalName != "" {
+		name = f.Column.OriginalName
+	}
+	for _, mf := range model.Fields {
+		if mf.DBName == name && mf.Type == f.Type {
+			return mf.Name, true
+		}
+	}
+	return "", false
+}
+
+func sameStructShape(a, b *Struct) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		fa, fb := a.Fields[i], b.Fields[i]
+		if fa.Name != fb.Name || fa.DBName != fb.DBName || fa.Type != fb.Type ||
+			fa.Tag() != fb.Tag() || fa.ArrayType != fb.ArrayType ||
+			fa.Column.GetIsSqlcSlice() != fb.Column.GetIsSqlcSlice() {
+			return false
+		}
+		if !sameStructShape(&Struct{Fields: fa.EmbedFields}, &Struct{Fields: fb.EmbedFields}) {
+			return false
+		}
+	}
+	return true
+}
+
 var cmdReturnsData = map[string]struct{}{
 	metadata.CmdBatchMany: {},
 	metadata.CmdBatchOne:  {},
@@ -351,7 +839,7 @@ func putOutColumns(query *plugin.Query) bool {
 // JSON tags: count, count_2, count_2
 //
 // This is unlikely to happen, so don't fix it yet
-func columnsToStruct(req *plugin.GenerateRequest, options *opts.Options, name string, columns []goColumn, useID bool) (*Struct, error) {
+func columnsToStruct(req *plugin.GenerateRequest, options *opts.Options, ids *typedIDIndex, qo queryOverrideIndex, name string, columns []goColumn, useID bool) (*Struct, error) {
 	gs := Struct{
 		Name: name,
 	}
@@ -389,7 +877,7 @@ func columnsToStruct(req *plugin.GenerateRequest, options *opts.Options, name st
 		if options.EmitJsonTags {
 			tags["json"] = JSONTagName(tagName, options)
 		}
-		addExtraGoStructTags(tags, req, options, c.Column)
+		addExtraGoStructTags(tags, req, options, qo, c.Column)
 		f := Field{
 			Name:   fieldName,
 			DBName: colName,
@@ -397,7 +885,9 @@ func columnsToStruct(req *plugin.GenerateRequest, options *opts.Options, name st
 			Column: c.Column,
 		}
 		if c.embed == nil {
-			f.Type = goType(req, options, c.Column)
+			f.Type = goType(req, options, ids, qo, c.Column)
+			f.ArrayType = goEnumArrayType(req, options, ids, qo, c.Column)
+			f.Converter = goTypeConverter(req, options, ids, qo, c.Column)
 		} else {
 			f.Type = c.embed.modelType
 			f.EmbedFields = c.embed.fields
@@ -1,12 +1,77 @@
 package golang
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/sqlc-dev/plugin-sdk-go/metadata"
 	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+
+	"github.com/sqlc-dev/sqlc-gen-go/internal/opts"
 )
 
+// generateFiles runs the plugin and returns the generated files by name.
+func generateFiles(t *testing.T, req *plugin.GenerateRequest) map[string]string {
+	t.Helper()
+	resp, err := Generate(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string, len(resp.Files))
+	for _, f := range resp.Files {
+		files[f.Name] = string(f.Contents)
+	}
+	return files
+}
+
+// testGeneratedCode runs the tests in src against the given generated files,
+// in the module example.com/db. The files may only import the standard
+// library, the packages of that module, which files in subdirectories
+// provide, and the required modules, e.g. "github.com/lib/pq v1.10.9". The
+// test is skipped when the required modules can't be downloaded.
+func testGeneratedCode(t *testing.T, files map[string]string, src string, requires ...string) {
+	t.Helper()
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/db\n\ngo 1.21\n"
+	if len(requires) > 0 {
+		files["go.mod"] += "\nrequire (\n\t" + strings.Join(requires, "\n\t") + "\n)\n"
+	}
+	files["generated_test.go"] = src
+	for name, contents := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env := append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off", "GOTOOLCHAIN=local")
+	for _, req := range requires {
+		cmd := exec.Command(gobin, "mod", "download", strings.Fields(req)[0])
+		cmd.Dir = dir
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("go mod download %s: %s\n%s", req, err, out)
+		}
+	}
+	cmd := exec.Command(gobin, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test: %s\n%s", err, out)
+	}
+}
+
 func TestPutOutColumns_ForZeroColumns(t *testing.T) {
 	tests := []struct {
 		cmd  string
@@ -76,3 +141,1452 @@ func TestPutOutColumns_AlwaysTrueWhenQueryHasColumns(t *testing.T) {
 		t.Error("should be true when we have columns")
 	}
 }
+
+func TestBuildEnums_Naming(t *testing.T) {
+	req := &plugin.GenerateRequest{
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{
+				{
+					Name: "public",
+					Enums: []*plugin.Enum{
+						{Name: "status", Vals: []string{"open", "A-1", "A_1"}},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		naming opts.EnumNaming
+		rename map[string]map[string]string
+		want   []string
+	}{
+		{
+			naming: "",
+			want:   []string{"StatusOpen", "StatusA1", "StatusA1"},
+		},
+		{
+			naming: opts.EnumNamingPrefix,
+			rename: map[string]map[string]string{"status": {"A_1": "a_underscore_1"}},
+			want:   []string{"StatusOpen", "StatusA1", "StatusAUnderscore1"},
+		},
+		{
+			naming: opts.EnumNamingSuffix,
+			want:   []string{"OpenStatus", "A1Status", "A1Status"},
+		},
+		{
+			naming: opts.EnumNamingValue,
+			rename: map[string]map[string]string{"status": {"A-1": "alpha_1", "A_1": "alpha_2"}},
+			want:   []string{"Open", "Alpha1", "Alpha2"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.naming), func(t *testing.T) {
+			options := &opts.Options{EnumNaming: tc.naming, EnumValueRename: tc.rename}
+			enums := buildEnums(req, options)
+			var got []string
+			for _, c := range enums[0].Constants {
+				got = append(got, c.Name)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("constant names mismatch;\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateEnumConstants(t *testing.T) {
+	req := &plugin.GenerateRequest{
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name: "public",
+				Enums: []*plugin.Enum{
+					{Name: "status", Vals: []string{"open", "A-1", "A_1"}},
+					{Name: "sign", Vals: []string{"+", "-"}},
+				},
+			}},
+		},
+	}
+	for _, tt := range []struct {
+		rename map[string]map[string]string
+		err    string
+	}{
+		{
+			nil,
+			"enum Sign: value \"+\" does not produce a Go identifier, set a name with enum_value_rename",
+		},
+		{
+			map[string]map[string]string{"sign": {"+": "plus", "-": "minus"}},
+			"enum constant name StatusA1 is used by both Status value \"A-1\" and Status value \"A_1\", set a different name with enum_value_rename",
+		},
+		{
+			map[string]map[string]string{"sign": {"+": "plus", "-": "minus"}, "status": {"A_1": "a_underscore_1"}},
+			"",
+		},
+	} {
+		options := &opts.Options{EnumValueRename: tt.rename}
+		var got string
+		if err := validate(options, buildEnums(req, options), nil, nil, nil, nil); err != nil {
+			got = err.Error()
+		}
+		if diff := cmp.Diff(tt.err, got); diff != "" {
+			t.Errorf("error mismatch;\n%s", diff)
+		}
+	}
+}
+
+func TestDomains(t *testing.T) {
+	columns := []*plugin.Column{
+		{Name: "email", Type: &plugin.Identifier{Name: "email"}, NotNull: true},
+		{Name: "backup_email", Type: &plugin.Identifier{Name: "email"}},
+		{Name: "contact", Type: &plugin.Identifier{Schema: "accounts", Name: "contact"}, NotNull: true},
+		{Name: "age", Type: &plugin.Identifier{Name: "age"}},
+	}
+	for _, tt := range []struct {
+		options string
+		domains []Domain
+		want    []string
+	}{
+		{
+			`{"package": "db", "domains": [
+				{"name": "email", "db_type": "text"},
+				{"name": "accounts.contact", "db_type": "public.email"},
+				{"name": "age", "db_type": "pg_catalog.int4"}
+			]}`,
+			nil,
+			[]string{"string", "sql.NullString", "string", "sql.NullInt32"},
+		},
+		{
+			`{"package": "db", "sql_package": "pgx/v5", "emit_domain_types": true, "emit_pointers_for_null_types": true, "domains": [
+				{"name": "email", "db_type": "text"},
+				{"name": "accounts.contact", "db_type": "public.email"},
+				{"name": "age", "db_type": "pg_catalog.int4"}
+			]}`,
+			[]Domain{{Name: "Age", Type: "int32"}, {Name: "Email", Type: "string"}},
+			[]string{"Email", "*Email", "Email", "*Age"},
+		},
+	} {
+		req := &plugin.GenerateRequest{
+			Settings:      &plugin.Settings{Engine: "postgresql"},
+			Catalog:       &plugin.Catalog{DefaultSchema: "public"},
+			PluginOptions: []byte(tt.options),
+		}
+		options, err := opts.Parse(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(tt.domains, buildDomains(req, options)); diff != "" {
+			t.Errorf("domains mismatch;\n%s", diff)
+		}
+		var got []string
+		for _, col := range columns {
+			got = append(got, goType(req, options, nil, nil, col))
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("column types mismatch;\n%s", diff)
+		}
+	}
+}
+
+func TestRegisterTypes(t *testing.T) {
+	users := &plugin.Identifier{Schema: "public", Name: "users"}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name:           "public",
+				Enums:          []*plugin.Enum{{Name: "mood", Vals: []string{"happy", "sad"}}},
+				CompositeTypes: []*plugin.CompositeType{{Name: "address"}, {Name: "point3d"}},
+				Tables: []*plugin.Table{
+					{Rel: users, Columns: []*plugin.Column{
+						{Name: "feeling", Type: &plugin.Identifier{Name: "happy_mood"}, NotNull: true, Table: users},
+						{Name: "home", Type: &plugin.Identifier{Name: "address"}, NotNull: true, Table: users},
+						{Name: "location", Type: &plugin.Identifier{Name: "point3d"}, NotNull: true, Table: users},
+						{Name: "bio", Type: &plugin.Identifier{Name: "short_note"}, Table: users},
+					}},
+				},
+			}},
+		},
+		PluginOptions: []byte(`{"package": "db", "sql_package": "pgx/v5", "emit_register_types": true,
+			"overrides": [{"db_type": "address", "go_type": "github.com/acme/geo.Address"}],
+			"domains": [
+				{"name": "email", "db_type": "text"},
+				{"name": "short_note", "db_type": "note"},
+				{"name": "note", "db_type": "text"},
+				{"name": "happy_mood", "db_type": "mood"}
+			]}`),
+	}
+	files := generateFiles(t, req)
+	want := `	for _, name := range []string{
+		"mood",
+		"_mood",
+		"happy_mood",
+		"address",
+		"note",
+		"short_note",
+	} {`
+	if !strings.Contains(files["db.go"], want) {
+		t.Errorf("RegisterTypes does not load the expected types:\n%s", files["db.go"])
+	}
+}
+
+func TestEnumArrays(t *testing.T) {
+	users := &plugin.Identifier{Schema: "public", Name: "users"}
+	moods := &plugin.Column{Name: "moods", Type: &plugin.Identifier{Name: "mood"}, NotNull: true, IsArray: true, ArrayDims: 1, Table: users}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name:   "public",
+				Enums:  []*plugin.Enum{{Name: "mood", Vals: []string{"happy", "sad"}}},
+				Tables: []*plugin.Table{{Rel: users, Columns: []*plugin.Column{moods}}},
+			}},
+		},
+		Queries: []*plugin.Query{{
+			Name:     "ListMoods",
+			Cmd:      metadata.CmdOne,
+			Text:     "SELECT moods FROM users WHERE moods && $1",
+			Filename: "query.sql",
+			Columns:  []*plugin.Column{moods},
+			Params:   []*plugin.Parameter{{Number: 1, Column: moods}},
+		}},
+		PluginOptions: []byte(`{"package": "db"}`),
+	}
+	files := generateFiles(t, req)
+	for _, want := range []string{
+		"row := q.db.QueryRowContext(ctx, listMoods, MoodArray(moods))",
+		"err := row.Scan((*MoodArray)(&moods))",
+	} {
+		if !strings.Contains(files["query.sql.go"], want) {
+			t.Errorf("MoodArray is not used in query.sql.go, want %q:\n%s", want, files["query.sql.go"])
+		}
+	}
+
+	testGeneratedCode(t, map[string]string{"models.go": files["models.go"]}, `package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEnumArray(t *testing.T) {
+	for _, tt := range []struct {
+		literal string
+		want    []string
+		err     bool
+	}{
+		{literal: "{}", want: []string{}},
+		{literal: "{happy}", want: []string{"happy"}},
+		{literal: "{happy,sad}", want: []string{"happy", "sad"}},
+		{literal: `+"`"+`{"very happy","a,b"}`+"`"+`, want: []string{"very happy", "a,b"}},
+		{literal: `+"`"+`{"say \"hi\"","back\\slash"}`+"`"+`, want: []string{`+"`"+`say "hi"`+"`"+`, `+"`"+`back\slash`+"`"+`}},
+		{literal: `+"`"+`{"NULL"}`+"`"+`, want: []string{"NULL"}},
+		{literal: "{happy,NULL}", err: true},
+		{literal: "{null}", err: true},
+		{literal: `+"`"+`{"happy`+"`"+`, err: true},
+		{literal: "happy", err: true},
+		{literal: "", err: true},
+	} {
+		got, err := parseEnumArray(tt.literal)
+		if (err != nil) != tt.err {
+			t.Errorf("parseEnumArray(%q): unexpected error %v", tt.literal, err)
+			continue
+		}
+		if !tt.err && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseEnumArray(%q) = %q, want %q", tt.literal, got, tt.want)
+		}
+	}
+}
+
+func TestFormatEnumArray(t *testing.T) {
+	for _, tt := range []struct {
+		elems []string
+		want  string
+	}{
+		{[]string{}, "{}"},
+		{[]string{"happy", "sad"}, `+"`"+`{"happy","sad"}`+"`"+`},
+		{[]string{"a,b", "NULL", `+"`"+`say "hi"`+"`"+`, `+"`"+`back\slash`+"`"+`}, `+"`"+`{"a,b","NULL","say \"hi\"","back\\slash"}`+"`"+`},
+	} {
+		got := formatEnumArray(tt.elems)
+		if got != tt.want {
+			t.Errorf("formatEnumArray(%q) = %s, want %s", tt.elems, got, tt.want)
+		}
+		back, err := parseEnumArray(got)
+		if err != nil || !reflect.DeepEqual(back, tt.elems) {
+			t.Errorf("parseEnumArray(%s) = %q, %v, want %q", got, back, err, tt.elems)
+		}
+	}
+}
+
+func TestMoodArray(t *testing.T) {
+	var moods MoodArray
+	if err := moods.Scan([]byte("{happy,sad}")); err != nil {
+		t.Fatal(err)
+	}
+	if want := (MoodArray{MoodHappy, MoodSad}); !reflect.DeepEqual(moods, want) {
+		t.Errorf("Scan = %v, want %v", moods, want)
+	}
+	if v, err := moods.Value(); err != nil || v != `+"`"+`{"happy","sad"}`+"`"+` {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+	if err := moods.Scan(nil); err != nil || moods != nil {
+		t.Errorf("Scan(nil) = %v, %v", moods, err)
+	}
+	if v, err := moods.Value(); err != nil || v != nil {
+		t.Errorf("Value of nil = %v, %v", v, err)
+	}
+}
+`)
+}
+
+func TestStrictEnums(t *testing.T) {
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name:  "public",
+				Enums: []*plugin.Enum{{Name: "mood", Vals: []string{"happy", "sad"}}},
+			}},
+		},
+		PluginOptions: []byte(`{"package": "db", "emit_strict_enums": true}`),
+	}
+	files := generateFiles(t, req)
+	testGeneratedCode(t, map[string]string{"models.go": files["models.go"]}, `package db
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMood(t *testing.T) {
+	var m Mood
+	if err := m.Scan([]byte("sad")); err != nil || m != MoodSad {
+		t.Errorf("Scan = %q, %v", m, err)
+	}
+	if err := m.UnmarshalText([]byte("happy")); err != nil || m != MoodHappy {
+		t.Errorf("UnmarshalText = %q, %v", m, err)
+	}
+	if text, err := MoodSad.MarshalText(); err != nil || string(text) != "sad" {
+		t.Errorf("MarshalText = %s, %v", text, err)
+	}
+	if v, err := MoodSad.Value(); err != nil || v != "sad" {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+	if MoodSad.String() != "sad" {
+		t.Errorf("String = %s", MoodSad.String())
+	}
+	if data, err := json.Marshal(MoodHappy); err != nil || string(data) != `+"`"+`"happy"`+"`"+` {
+		t.Errorf("json.Marshal = %s, %v", data, err)
+	}
+	if err := json.Unmarshal([]byte(`+"`"+`"sad"`+"`"+`), &m); err != nil || m != MoodSad {
+		t.Errorf("json.Unmarshal = %q, %v", m, err)
+	}
+}
+
+func TestMoodInvalid(t *testing.T) {
+	for name, unmarshal := range map[string]func(*Mood) error{
+		"Scan":          func(m *Mood) error { return m.Scan("angry") },
+		"Scan bytes":    func(m *Mood) error { return m.Scan([]byte("angry")) },
+		"UnmarshalText": func(m *Mood) error { return m.UnmarshalText([]byte("angry")) },
+		"json":          func(m *Mood) error { return json.Unmarshal([]byte(`+"`"+`"angry"`+"`"+`), m) },
+	} {
+		m := MoodHappy
+		err := unmarshal(&m)
+		var invalid *InvalidEnumValueError
+		if !errors.As(err, &invalid) {
+			t.Errorf("%s: got error %v, want an *InvalidEnumValueError", name, err)
+			continue
+		}
+		if invalid.Type != "Mood" || invalid.Value != "angry" {
+			t.Errorf("%s: got %+v", name, invalid)
+		}
+		if err.Error() != `+"`"+`invalid Mood value: "angry"`+"`"+` {
+			t.Errorf("%s: got message %q", name, err.Error())
+		}
+		if m != MoodHappy {
+			t.Errorf("%s: the invalid value was stored: %q", name, m)
+		}
+	}
+}
+
+func TestNullMood(t *testing.T) {
+	var ns NullMood
+	if err := json.Unmarshal([]byte("null"), &ns); err != nil || ns.Valid {
+		t.Errorf("json.Unmarshal(null) = %+v, %v", ns, err)
+	}
+	if data, err := json.Marshal(ns); err != nil || string(data) != "null" {
+		t.Errorf("json.Marshal = %s, %v", data, err)
+	}
+	if err := ns.UnmarshalText([]byte("sad")); err != nil || !ns.Valid || ns.Mood != MoodSad {
+		t.Errorf("UnmarshalText = %+v, %v", ns, err)
+	}
+	if ns.String() != "sad" {
+		t.Errorf("String = %s", ns.String())
+	}
+	var invalid *InvalidEnumValueError
+	if err := ns.Scan("angry"); !errors.As(err, &invalid) {
+		t.Errorf("Scan of an invalid value: got %v", err)
+	}
+	if err := ns.Scan(nil); err != nil || ns.Valid {
+		t.Errorf("Scan(nil) = %+v, %v", ns, err)
+	}
+}
+`)
+}
+
+func TestModelDescriptors(t *testing.T) {
+	users := &plugin.Identifier{Schema: "public", Name: "users"}
+	events := &plugin.Identifier{Schema: "audit", Name: "events"}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{
+				{Name: "public", Tables: []*plugin.Table{{Rel: users, Columns: []*plugin.Column{
+					{Name: "id", Type: &plugin.Identifier{Name: "bigserial"}, NotNull: true, Table: users},
+					{Name: "full_name", Type: &plugin.Identifier{Schema: "pg_catalog", Name: "varchar"}, Table: users},
+				}}}},
+				{Name: "audit", Tables: []*plugin.Table{{Rel: events, Columns: []*plugin.Column{
+					{Name: "id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true, Table: events},
+				}}}},
+			},
+		},
+		PluginOptions: []byte(`{"package": "db", "emit_model_descriptors": true}`),
+	}
+	models := generateFiles(t, req)["models.go"]
+	for _, want := range []string{
+		"func (AuditEvent) TableName() string {\n\treturn \"audit.events\"\n}",
+		"func (User) TableName() string {\n\treturn \"users\"\n}",
+		"var UserColumns = struct {\n\tID       ColumnDescriptor\n\tFullName ColumnDescriptor\n}{",
+		"\tFullName: ColumnDescriptor{Name: \"full_name\", Field: \"FullName\", DBType: \"pg_catalog.varchar\"},",
+		"func (User) ColumnDescriptors() []ColumnDescriptor {\n\treturn []ColumnDescriptor{\n\t\tUserColumns.ID,\n\t\tUserColumns.FullName,\n\t}\n}",
+	} {
+		if !strings.Contains(models, want) {
+			t.Errorf("models.go does not contain %q:\n%s", want, models)
+		}
+	}
+	if n := strings.Count(models, "type ColumnDescriptor struct"); n != 1 {
+		t.Errorf("ColumnDescriptor is declared %d times", n)
+	}
+}
+
+func TestQueryRegistry(t *testing.T) {
+	authors := &plugin.Identifier{Schema: "public", Name: "authors"}
+	id := &plugin.Column{Name: "id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true, Table: authors}
+	name := &plugin.Column{Name: "name", Type: &plugin.Identifier{Name: "text"}, NotNull: true, Table: authors}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name:   "public",
+				Tables: []*plugin.Table{{Rel: authors, Columns: []*plugin.Column{id, name}}},
+			}},
+		},
+		Queries: []*plugin.Query{
+			{
+				Name:     "GetAuthorName",
+				Cmd:      metadata.CmdOne,
+				Text:     "SELECT name FROM authors WHERE id = $1",
+				Filename: "query.sql",
+				Columns:  []*plugin.Column{name},
+				Params:   []*plugin.Parameter{{Number: 1, Column: id}},
+			},
+			{
+				Name:            "CreateAuthors",
+				Cmd:             metadata.CmdCopyFrom,
+				Text:            "INSERT INTO authors (id, name) VALUES ($1, $2)",
+				Filename:        "query.sql",
+				InsertIntoTable: authors,
+				Params:          []*plugin.Parameter{{Number: 1, Column: id}, {Number: 2, Column: name}},
+			},
+		},
+	}
+	for _, sqlPackage := range []string{opts.SQLPackagePGXV4, opts.SQLPackagePGXV5} {
+		req.PluginOptions = []byte(`{"package": "db", "emit_query_registry": true, "emit_query_fingerprints": true, "sql_package": "` + sqlPackage + `"}`)
+		files := generateFiles(t, req)
+		registry := files["registry.go"]
+		for _, want := range []string{
+			"Name:        \"GetAuthorName\",\n\t\t\tCmd:         \":one\",\n\t\t\tSQL:         getAuthorName,",
+			"Params: []ParamDescriptor{\n\t\t\t\t{Name: \"id\", Type: \"int64\"},\n\t\t\t},\n\t\t\tColumns: []string{\"name\"},",
+			"Name:        \"CreateAuthors\",\n\t\t\tCmd:         \":copyfrom\",\n\t\t\tSQL:         \"\",",
+		} {
+			if !strings.Contains(registry, want) {
+				t.Errorf("%s: registry.go does not contain %q:\n%s", sqlPackage, want, registry)
+			}
+		}
+		// Every query constant the registry refers to must be declared
+		for _, line := range strings.Split(registry, "\n") {
+			key, value, _ := strings.Cut(strings.TrimSpace(line), ":")
+			if key != "SQL" && key != "Fingerprint" {
+				continue
+			}
+			constant := strings.TrimSuffix(strings.TrimSpace(value), ",")
+			if constant == `""` {
+				continue
+			}
+			if !strings.Contains(files["query.sql.go"], "const "+constant+" = ") {
+				t.Errorf("%s: registry.go refers to the undeclared constant %s", sqlPackage, constant)
+			}
+		}
+	}
+}
+
+func TestModelConversions(t *testing.T) {
+	authors := &plugin.Identifier{Schema: "public", Name: "authors"}
+	id := &plugin.Column{Name: "id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true, Table: authors}
+	name := &plugin.Column{Name: "name", Type: &plugin.Identifier{Name: "text"}, NotNull: true, Table: authors}
+	bio := &plugin.Column{Name: "bio", Type: &plugin.Identifier{Name: "text"}, Table: authors}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name:   "public",
+				Tables: []*plugin.Table{{Rel: authors, Columns: []*plugin.Column{id, name, bio}}},
+			}},
+		},
+		Queries: []*plugin.Query{
+			{
+				Name:     "ListAuthorNames",
+				Cmd:      metadata.CmdMany,
+				Text:     "SELECT id, name FROM authors",
+				Filename: "query.sql",
+				Columns:  []*plugin.Column{id, name},
+			},
+			{
+				Name:     "ListAuthorsWithBookCount",
+				Cmd:      metadata.CmdMany,
+				Text:     "SELECT authors.*, count(books.id) AS book_count FROM authors JOIN books ON books.author_id = authors.id GROUP BY authors.id",
+				Filename: "query.sql",
+				Columns:  []*plugin.Column{id, name, bio, {Name: "book_count", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true}},
+			},
+			{
+				Name:     "UpdateAuthorName",
+				Cmd:      metadata.CmdExec,
+				Text:     "UPDATE authors SET name = $1 WHERE id = $2",
+				Filename: "query.sql",
+				Params:   []*plugin.Parameter{{Number: 1, Column: name}, {Number: 2, Column: id}},
+			},
+		},
+		PluginOptions: []byte(`{"package": "db", "emit_model_conversions": true}`),
+	}
+	code := generateFiles(t, req)["query.sql.go"]
+	for _, want := range []string{
+		// A subset of the columns converts from the model only
+		"func (r *ListAuthorNamesRow) FromAuthor(m Author) {\n\tr.ID = m.ID\n\tr.Name = m.Name\n}",
+		"func (r *UpdateAuthorNameParams) FromAuthor(m Author) {\n\tr.Name = m.Name\n\tr.ID = m.ID\n}",
+		// A superset of the columns converts to the model only
+		"func (r ListAuthorsWithBookCountRow) ToAuthor() Author {\n\treturn Author{\n\t\tID:   r.ID,\n\t\tName: r.Name,\n\t\tBio:  r.Bio,\n\t}\n}",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", want, code)
+		}
+	}
+	for _, unwanted := range []string{
+		"func (r ListAuthorNamesRow) ToAuthor()",
+		"func (r UpdateAuthorNameParams) ToAuthor()",
+		"func (r *ListAuthorsWithBookCountRow) FromAuthor(",
+	} {
+		if strings.Contains(code, unwanted) {
+			t.Errorf("query.sql.go unexpectedly contains %q", unwanted)
+		}
+	}
+}
+
+func TestModelParams(t *testing.T) {
+	authors := &plugin.Identifier{Schema: "public", Name: "authors"}
+	id := &plugin.Column{Name: "id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true, Table: authors}
+	name := &plugin.Column{Name: "name", Type: &plugin.Identifier{Name: "text"}, NotNull: true, Table: authors}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name:   "public",
+				Tables: []*plugin.Table{{Rel: authors, Columns: []*plugin.Column{id, name}}},
+			}},
+		},
+		Queries: []*plugin.Query{
+			{
+				Name:    "UpdateAuthor",
+				Cmd:     metadata.CmdOne,
+				Text:    "UPDATE authors SET name = $1 WHERE id = $2 RETURNING id, name",
+				Columns: []*plugin.Column{id, name},
+				Params:  []*plugin.Parameter{{Number: 1, Column: name}, {Number: 2, Column: id}},
+			},
+			{
+				Name:    "RenameAuthor",
+				Cmd:     metadata.CmdOne,
+				Text:    "UPDATE authors SET name = $1 WHERE name = $2 RETURNING name, id",
+				Columns: []*plugin.Column{name, id},
+				Params:  []*plugin.Parameter{{Number: 1, Column: name}, {Number: 2, Column: &plugin.Column{Name: "old_name", Type: &plugin.Identifier{Name: "text"}, NotNull: true}}},
+			},
+		},
+		PluginOptions: []byte(`{"package": "db", "emit_model_params": true}`),
+	}
+	options, err := opts.Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries, err := buildQueries(req, options, nil, buildQueryOverrides(req, options), buildStructs(req, options, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rename, update := queries[0], queries[1]
+	for _, tt := range []struct{ got, want string }{
+		// Parameters match a model in any order, result columns in the
+		// order of the model only.
+		{update.Arg.Type(), "Author"},
+		{update.Arg.Params(), "arg.Name,arg.ID"},
+		{update.Ret.Type(), "Author"},
+		{rename.Arg.Type(), "RenameAuthorParams"},
+		{rename.Ret.Type(), "RenameAuthorRow"},
+	} {
+		if diff := cmp.Diff(tt.want, tt.got); diff != "" {
+			t.Errorf("generated code mismatch;\n%s", diff)
+		}
+	}
+
+	req.Queries[1].Comments = []string{" @sqlc-gen-go:model-params"}
+	req.PluginOptions = []byte(`{"package": "db"}`)
+	if options, err = opts.Parse(req); err != nil {
+		t.Fatal(err)
+	}
+	_, err = buildQueries(req, options, nil, buildQueryOverrides(req, options), buildStructs(req, options, nil))
+	if diff := cmp.Diff("query RenameAuthor: annotation @sqlc-gen-go:model-params: parameters do not match the columns of a table", fmt.Sprint(err)); diff != "" {
+		t.Errorf("error mismatch;\n%s", diff)
+	}
+}
+
+func TestNestEmbed(t *testing.T) {
+	authors := &plugin.Identifier{Schema: "public", Name: "authors"}
+	books := &plugin.Identifier{Schema: "public", Name: "books"}
+	authorID := &plugin.Column{Name: "id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true, Table: authors}
+	avatar := &plugin.Column{Name: "avatar", Type: &plugin.Identifier{Name: "bytea"}, NotNull: true, Table: authors}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name: "public",
+				Tables: []*plugin.Table{
+					{Rel: authors, Columns: []*plugin.Column{authorID, avatar}},
+					{Rel: books, Columns: []*plugin.Column{
+						{Name: "id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true, Table: books},
+						{Name: "title", Type: &plugin.Identifier{Name: "text"}, NotNull: true, Table: books},
+					}},
+				},
+			}},
+		},
+		Queries: []*plugin.Query{{
+			Name:     "ListAuthorsWithBooks",
+			Cmd:      metadata.CmdMany,
+			Comments: []string{" @sqlc-gen-go:nest=books"},
+			Text:     "SELECT authors.id, sqlc.embed(books) FROM authors JOIN books ON books.author_id = authors.id ORDER BY authors.id",
+			Filename: "query.sql",
+			Columns: []*plugin.Column{
+				authorID,
+				{Name: "books", EmbedTable: books, Type: &plugin.Identifier{}},
+			},
+		}},
+		PluginOptions: []byte(`{"package": "db", "omit_unused_structs": true}`),
+	}
+	files := generateFiles(t, req)
+	for _, want := range []string{
+		"type ListAuthorsWithBooksRow struct {\n\tID    int64\n\tBooks []Book\n}",
+		"if err := rows.Scan(&i.ID, &child.ID, &child.Title); err != nil {",
+		"if n := len(items); n > 0 && items[n-1].ID == i.ID {\n\t\t\titems[n-1].Books = append(items[n-1].Books, child)\n\t\t\tcontinue\n\t\t}\n\t\ti.Books = []Book{child}",
+	} {
+		if !strings.Contains(files["query.sql.go"], want) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", want, files["query.sql.go"])
+		}
+	}
+	// The nested model is used by the result
+	if !strings.Contains(files["models.go"], "type Book struct {") {
+		t.Errorf("models.go does not declare Book:\n%s", files["models.go"])
+	}
+
+	// Parents are told apart by comparing the first column
+	req.Queries[0].Columns[0] = avatar
+	_, err := Generate(context.Background(), req)
+	want := "query ListAuthorsWithBooks: annotation @sqlc-gen-go:nest: the first column avatar identifies the parent and its type []byte can't be compared"
+	if diff := cmp.Diff(want, fmt.Sprint(err)); diff != "" {
+		t.Errorf("error mismatch;\n%s", diff)
+	}
+}
+
+func TestNullableEmbeds(t *testing.T) {
+	authors := &plugin.Identifier{Schema: "public", Name: "authors"}
+	books := &plugin.Identifier{Schema: "public", Name: "books"}
+	authorID := &plugin.Column{Name: "id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true, Table: authors}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name: "public",
+				Tables: []*plugin.Table{
+					{Rel: authors, Columns: []*plugin.Column{authorID}},
+					{Rel: books, Columns: []*plugin.Column{
+						{Name: "id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true, Table: books},
+						{Name: "published_at", Type: &plugin.Identifier{Name: "timestamptz"}, NotNull: true, Table: books},
+					}},
+				},
+			}},
+		},
+		Queries: []*plugin.Query{
+			{
+				Name:     "GetAuthorWithLatestBook",
+				Cmd:      metadata.CmdOne,
+				Comments: []string{" @sqlc-gen-go:nullable-embed=books"},
+				Text:     "SELECT authors.id, sqlc.embed(books) FROM authors LEFT JOIN books ON books.author_id = authors.id WHERE authors.id = $1",
+				Filename: "query.sql",
+				Columns:  []*plugin.Column{authorID, {Name: "books", EmbedTable: books, Type: &plugin.Identifier{}}},
+				Params:   []*plugin.Parameter{{Number: 1, Column: authorID}},
+			},
+			{
+				Name:     "ListAuthorsWithBooks",
+				Cmd:      metadata.CmdMany,
+				Comments: []string{" @sqlc-gen-go:nest=books", " @sqlc-gen-go:nullable-embed=books"},
+				Text:     "SELECT authors.id, sqlc.embed(books) FROM authors LEFT JOIN books ON books.author_id = authors.id ORDER BY authors.id",
+				Filename: "query.sql",
+				Columns:  []*plugin.Column{authorID, {Name: "books", EmbedTable: books, Type: &plugin.Identifier{}}},
+			},
+		},
+	}
+	for _, tt := range []struct {
+		sqlPackage string
+		want       []string
+	}{
+		{
+			opts.SQLPackageStandard,
+			[]string{
+				"import (\n\t\"context\"\n\t\"time\"\n)",
+				"var bookNull struct {\n\t\tID          *int64\n\t\tPublishedAt *time.Time\n\t}",
+				"if bookNull.ID != nil && bookNull.PublishedAt != nil {\n\t\ti.Book = &Book{\n\t\t\tID:          *bookNull.ID,\n\t\t\tPublishedAt: *bookNull.PublishedAt,\n\t\t}\n\t}",
+				"if child.ID != nil && child.PublishedAt != nil {\n\t\t\ti.Books = []Book{{\n\t\t\t\tID:          *child.ID,\n\t\t\t\tPublishedAt: *child.PublishedAt,\n\t\t\t}}\n\t\t}",
+			},
+		},
+		{
+			opts.SQLPackagePGXV5,
+			[]string{
+				"import (\n\t\"context\"\n\n\t\"github.com/jackc/pgx/v5/pgtype\"\n)",
+				"PublishedAt *pgtype.Timestamptz",
+			},
+		},
+	} {
+		req.PluginOptions = []byte(`{"package": "db", "sql_package": "` + tt.sqlPackage + `"}`)
+		code := generateFiles(t, req)["query.sql.go"]
+		for _, want := range tt.want {
+			if !strings.Contains(code, want) {
+				t.Errorf("%s: query.sql.go does not contain %q:\n%s", tt.sqlPackage, want, code)
+			}
+		}
+	}
+}
+
+func TestOptionalParams(t *testing.T) {
+	authors := &plugin.Identifier{Schema: "public", Name: "authors"}
+	flag := func(name string) *plugin.Column {
+		return &plugin.Column{Name: name, Type: &plugin.Identifier{Name: "bool"}, NotNull: true}
+	}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog:  &plugin.Catalog{DefaultSchema: "public"},
+		Queries: []*plugin.Query{{
+			Name:     "PatchAuthor",
+			Cmd:      metadata.CmdExec,
+			Text:     "UPDATE authors SET bio = CASE WHEN @set_bio THEN @bio ELSE bio END, name = CASE WHEN @set_name THEN @name ELSE name END WHERE id = @id",
+			Filename: "query.sql",
+			Params: []*plugin.Parameter{
+				{Number: 1, Column: flag("set_bio")},
+				{Number: 2, Column: &plugin.Column{Name: "bio", Type: &plugin.Identifier{Name: "text"}, Table: authors}},
+				{Number: 3, Column: flag("set_name")},
+				{Number: 4, Column: &plugin.Column{Name: "name", Type: &plugin.Identifier{Name: "text"}, Table: authors}},
+				{Number: 5, Column: &plugin.Column{Name: "id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true, Table: authors}},
+			},
+		}},
+		PluginOptions: []byte(`{"package": "db", "emit_optional_params": true, "overrides": [
+			{"query": "PatchAuthor", "param": "name", "go_type": {"import": "github.com/acme/types", "type": "NullName"}}
+		]}`),
+	}
+	files := generateFiles(t, req)
+	for _, want := range []string{
+		"type PatchAuthorParams struct {\n\tBio  Optional[string]\n\tName Optional[types.NullName]\n\tID   int64\n}",
+		"_, err := q.db.ExecContext(ctx, patchAuthor,\n\t\targ.Bio.IsSet(),\n\t\targ.Bio,\n\t\targ.Name.IsSet(),\n\t\targ.Name,\n\t\targ.ID,\n\t)",
+	} {
+		if !strings.Contains(files["query.sql.go"], want) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", want, files["query.sql.go"])
+		}
+	}
+	if !strings.Contains(files["models.go"], "type Optional[T any] struct {") {
+		t.Errorf("models.go does not declare Optional:\n%s", files["models.go"])
+	}
+	testGeneratedCode(t, map[string]string{"models.go": files["models.go"]}, `package db
+
+import (
+	"database/sql/driver"
+	"net/netip"
+	"testing"
+)
+
+func TestOptional(t *testing.T) {
+	var o Optional[string]
+	if o.IsSet() {
+		t.Error("the zero Optional is set")
+	}
+	o.Set("bio")
+	if v, ok := o.Get(); !o.IsSet() || !ok || v != "bio" {
+		t.Errorf("Set: got %q, %v", v, ok)
+	}
+	if v, err := o.Value(); err != nil || v != driver.Value("bio") {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+	o.SetNull()
+	if _, ok := o.Get(); !o.IsSet() || ok {
+		t.Error("SetNull: the Optional is not set to NULL")
+	}
+	if v, err := o.Value(); err != nil || v != nil {
+		t.Errorf("Value of NULL = %v, %v", v, err)
+	}
+	o.Unset()
+	if o.IsSet() {
+		t.Error("Unset: the Optional is still set")
+	}
+}
+
+type name string
+
+func TestOptionalValue(t *testing.T) {
+	var n Optional[name]
+	n.Set("bio")
+	if v, err := n.Value(); err != nil || v != driver.Value("bio") {
+		t.Errorf("Value of a string kind = %#v, %v", v, err)
+	}
+	addr := netip.MustParseAddr("10.0.0.1")
+	var a Optional[netip.Addr]
+	a.Set(addr)
+	if v, err := a.Value(); err != nil || v != any(addr) {
+		t.Errorf("Value of a non-Valuer = %#v, %v", v, err)
+	}
+}
+`)
+}
+
+func TestGenericOverrideImports(t *testing.T) {
+	users := &plugin.Identifier{Name: "users"}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name: "public",
+				Tables: []*plugin.Table{{Rel: users, Columns: []*plugin.Column{
+					{Name: "manager_id", Type: &plugin.Identifier{Name: "text"}, Table: users},
+				}}},
+			}},
+		},
+		PluginOptions: []byte(`{"package": "db", "overrides": [
+			{"column": "users.manager_id", "go_type": "example.com/db/acme/opt.Option[example.com/db/other/opt.ID]"}
+		]}`),
+	}
+	files := generateFiles(t, req)
+	for _, want := range []string{
+		"\"example.com/db/acme/opt\"",
+		"opt2 \"example.com/db/other/opt\"",
+		"ManagerID opt.Option[opt2.ID]",
+	} {
+		if !strings.Contains(files["models.go"], want) {
+			t.Errorf("models.go does not contain %q:\n%s", want, files["models.go"])
+		}
+	}
+	testGeneratedCode(t, map[string]string{
+		"models.go":        files["models.go"],
+		"acme/opt/opt.go":  "package opt\n\ntype Option[T any] struct{ Value T }\n",
+		"other/opt/opt.go": "package opt\n\ntype ID string\n",
+	}, `package db
+
+import "testing"
+
+func TestUser(t *testing.T) {
+	u := User{}
+	u.ManagerID.Value = "42"
+	if u.ManagerID.Value != "42" {
+		t.Error("the manager ID is not set")
+	}
+}
+`)
+}
+
+func TestParseQueryAnnotations(t *testing.T) {
+	comments := []string{
+		" GetUser returns a user.",
+		" @sqlc-gen-go:result-pointer",
+		" @sqlc-gen-go:params-struct=GetUserInput",
+		" @sqlc-gen-go:deprecated=use GetAccount.",
+	}
+	options := &opts.Options{}
+	a, err := parseQueryAnnotations("GetUser", comments, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Options.EmitResultStructPointers || options.EmitResultStructPointers {
+		t.Error("result-pointer should only apply to the query options")
+	}
+	if diff := cmp.Diff([]string{" GetUser returns a user."}, a.Comments); diff != "" {
+		t.Errorf("comments mismatch;\n%s", diff)
+	}
+	if a.ParamsStruct != "GetUserInput" || a.Deprecated != "use GetAccount." {
+		t.Errorf("unexpected annotations: %+v", a)
+	}
+
+	for _, bad := range []string{"@sqlc-gen-go:unknown", "@sqlc-gen-go:result-pointer=true", "@sqlc-gen-go:params-struct=input"} {
+		if _, err := parseQueryAnnotations("GetUser", []string{bad}, options); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestShareQueryStructs(t *testing.T) {
+	row := func(name string, fields ...string) QueryValue {
+		s := &Struct{Name: name}
+		for _, f := range fields {
+			s.Fields = append(s.Fields, Field{Name: f, Type: "string", Column: &plugin.Column{}})
+		}
+		return QueryValue{Emit: true, Struct: s}
+	}
+	queries := []Query{
+		{Cmd: metadata.CmdOne, Ret: row("ARow", "ID", "Name")},
+		{Cmd: metadata.CmdOne, Ret: row("BRow", "ID", "Name")},
+		{Cmd: metadata.CmdOne, Ret: row("CRow", "ID")},
+	}
+	if err := shareQueryStructs(queries, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if queries[0].Ret.Shared || !queries[1].Ret.Shared || queries[1].Ret.Type() != "ARow" || queries[2].Ret.Shared {
+		t.Errorf("unexpected sharing: %+v", queries)
+	}
+
+	named := row("Summary", "ID")
+	other := row("Summary", "ID", "Name")
+	queries = []Query{{Cmd: metadata.CmdOne, Ret: named}, {Cmd: metadata.CmdOne, Ret: other}}
+	err := shareQueryStructs(queries, map[*Struct]struct{}{named.Struct: {}, other.Struct: {}}, false)
+	if err == nil {
+		t.Error("expected an error for a struct name used with different columns")
+	}
+}
+
+func TestQueryOverrides(t *testing.T) {
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog:  &plugin.Catalog{DefaultSchema: "public"},
+		PluginOptions: []byte(`{"package": "db", "overrides": [
+			{"query": "Count*", "result": "total", "go_type": "int"},
+			{"query": "CountInvoices", "param": "customer_id", "go_type": "github.com/google/uuid.UUID"}
+		]}`),
+		Queries: []*plugin.Query{{
+			Name: "CountInvoices",
+			Cmd:  metadata.CmdOne,
+			Columns: []*plugin.Column{
+				{Name: "total", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true},
+			},
+			Params: []*plugin.Parameter{
+				{Number: 1, Column: &plugin.Column{Name: "customer_id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true}},
+			},
+		}},
+	}
+	options, err := opts.Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries, err := buildQueries(req, options, nil, buildQueryOverrides(req, options), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("uuid.UUID", queries[0].Arg.Type()); diff != "" {
+		t.Errorf("param type mismatch;\n%s", diff)
+	}
+	if diff := cmp.Diff("int", queries[0].Ret.Type()); diff != "" {
+		t.Errorf("result type mismatch;\n%s", diff)
+	}
+}
+
+func TestConverters(t *testing.T) {
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog:  &plugin.Catalog{DefaultSchema: "public"},
+		PluginOptions: []byte(`{"package": "db", "sql_package": "pgx/v5", "overrides": [
+			{"db_type": "interval", "go_type": "time.Duration", "encode": "github.com/acme/pgconv.FromDuration", "decode": "github.com/acme/pgconv.ToDuration"}
+		]}`),
+		Queries: []*plugin.Query{{
+			Name: "GetTimeout",
+			Cmd:  metadata.CmdOne,
+			Columns: []*plugin.Column{
+				{Name: "timeout", Type: &plugin.Identifier{Name: "interval"}, NotNull: true},
+			},
+			Params: []*plugin.Parameter{
+				{Number: 1, Column: &plugin.Column{Name: "timeout", Type: &plugin.Identifier{Name: "interval"}, NotNull: true}},
+			},
+		}},
+	}
+	options, err := opts.Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries, err := buildQueries(req, options, nil, buildQueryOverrides(req, options), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := queries[0]
+	for _, tt := range []struct{ got, want string }{
+		{q.Arg.Params(), "pgconv.FromDuration(timeout)"},
+		{q.Ret.ScanVars(), "var timeoutNative pgtype.Interval"},
+		{q.Ret.Scan(), "&timeoutNative"},
+		{q.Ret.ScanDecode(""), "if err == nil {\ntimeout, err = pgconv.ToDuration(timeoutNative)\n}"},
+	} {
+		if diff := cmp.Diff(tt.want, tt.got); diff != "" {
+			t.Errorf("generated code mismatch;\n%s", diff)
+		}
+	}
+}
+
+func TestTypedIDs(t *testing.T) {
+	users := &plugin.Identifier{Schema: "public", Name: "users"}
+	posts := &plugin.Identifier{Schema: "public", Name: "posts"}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name: "public",
+				Tables: []*plugin.Table{
+					{Rel: users, Columns: []*plugin.Column{
+						{Name: "id", Type: &plugin.Identifier{Name: "bigserial"}, NotNull: true, Table: users},
+					}},
+					{Rel: posts, Columns: []*plugin.Column{
+						{Name: "id", Type: &plugin.Identifier{Name: "text"}, NotNull: true, Table: posts},
+						{Name: "user_id", Type: &plugin.Identifier{Name: "bigint"}, Table: posts},
+					}},
+				},
+			}},
+		},
+		PluginOptions: []byte(`{"package": "db", "typed_ids": {"columns": "*.id", "foreign_keys": "*_id"}}`),
+	}
+	options, err := opts.Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, index, err := buildTypedIDs(req, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TypedID{
+		{Name: "PostID", Type: "string", DBName: "posts.id", NullType: "sql.NullString", NullField: "String", ValueType: "string"},
+		{Name: "UserID", Type: "int64", DBName: "users.id", NullType: "sql.NullInt64", NullField: "Int64", ValueType: "int64"},
+	}
+	if diff := cmp.Diff(want, ids); diff != "" {
+		t.Errorf("typed IDs mismatch;\n%s", diff)
+	}
+	var types []string
+	for _, s := range buildStructs(req, options, index) {
+		for _, f := range s.Fields {
+			types = append(types, f.Type)
+		}
+	}
+	if diff := cmp.Diff([]string{"PostID", "*UserID", "UserID"}, types); diff != "" {
+		t.Errorf("field types mismatch;\n%s", diff)
+	}
+}
+
+func TestTypedIDsUnsigned(t *testing.T) {
+	users := &plugin.Identifier{Name: "users"}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "mysql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "app",
+			Schemas: []*plugin.Schema{{
+				Name: "app",
+				Tables: []*plugin.Table{{Rel: users, Columns: []*plugin.Column{
+					{Name: "id", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true, Unsigned: true, Table: users},
+				}}},
+			}},
+		},
+		PluginOptions: []byte(`{"package": "db", "typed_ids": {"columns": "*.id"}}`),
+	}
+	options, err := opts.Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = buildTypedIDs(req, options)
+	want := "typed_ids: ID column users.id has type uint64, which has no ID type; exclude it from the `columns` pattern"
+	if err == nil || err.Error() != want {
+		t.Errorf("expected error %q, got %v", want, err)
+	}
+}
+
+func TestElementOverrides(t *testing.T) {
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog:  &plugin.Catalog{DefaultSchema: "public"},
+		PluginOptions: []byte(`{"package": "db", "overrides": [
+			{"db_type": "uuid", "go_type": "string"},
+			{"db_type": "uuid", "go_element_type": "github.com/google/uuid.UUID"}
+		]}`),
+		Queries: []*plugin.Query{{
+			Name: "ListGrids",
+			Cmd:  metadata.CmdOne,
+			Columns: []*plugin.Column{
+				{Name: "owner", Type: &plugin.Identifier{Name: "uuid"}, NotNull: true},
+				{Name: "cells", Type: &plugin.Identifier{Name: "uuid"}, NotNull: true, IsArray: true, ArrayDims: 2},
+			},
+			Params: []*plugin.Parameter{
+				{Number: 1, Column: &plugin.Column{Name: "ids", Type: &plugin.Identifier{Name: "uuid"}, NotNull: true, IsArray: true}},
+			},
+		}},
+	}
+	options, err := opts.Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries, err := buildQueries(req, options, nil, buildQueryOverrides(req, options), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := queries[0]
+	for _, tt := range []struct{ got, want string }{
+		{q.Arg.Type(), "[]uuid.UUID"},
+		{q.Arg.Params(), "pq.Array(ids)"},
+		{q.Ret.Struct.Fields[0].Type, "string"},
+		{q.Ret.Struct.Fields[1].Type, "[][]uuid.UUID"},
+	} {
+		if diff := cmp.Diff(tt.want, tt.got); diff != "" {
+			t.Errorf("generated code mismatch;\n%s", diff)
+		}
+	}
+}
+
+func TestPQArrays(t *testing.T) {
+	query := func(col *plugin.Column) *plugin.Query {
+		return &plugin.Query{
+			Name:     "GetGroup",
+			Cmd:      metadata.CmdOne,
+			Text:     "SELECT ids FROM groups WHERE ids && $1",
+			Filename: "query.sql",
+			Columns:  []*plugin.Column{col},
+			Params: []*plugin.Parameter{
+				{Number: 1, Column: &plugin.Column{Name: "wanted", Type: &plugin.Identifier{Name: "int8"}, NotNull: true, IsArray: true, ArrayDims: 1}},
+			},
+		}
+	}
+	for _, tt := range []struct {
+		elem string
+		col  *plugin.Column
+		err  string
+	}{
+		{
+			"int",
+			&plugin.Column{Name: "ids", Type: &plugin.Identifier{Name: "int8"}, NotNull: true, IsArray: true, ArrayDims: 1},
+			"column GetGroup.ids: pq.Array can't scan elements of type int, use one of bool, float32, float64, int32, int64, string, []byte or a type implementing sql.Scanner",
+		},
+		{
+			"int64",
+			&plugin.Column{Name: "ids", Type: &plugin.Identifier{Name: "int8"}, NotNull: true, IsArray: true, ArrayDims: 2},
+			"column GetGroup.ids: pq.Array can't scan multi-dimensional arrays, use a go_type override or sql_package pgx/v5",
+		},
+	} {
+		req := &plugin.GenerateRequest{
+			Settings:      &plugin.Settings{Engine: "postgresql"},
+			Catalog:       &plugin.Catalog{DefaultSchema: "public"},
+			Queries:       []*plugin.Query{query(tt.col)},
+			PluginOptions: []byte(`{"package": "db", "overrides": [{"db_type": "int8", "go_element_type": "` + tt.elem + `"}]}`),
+		}
+		_, err := Generate(context.Background(), req)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("expected error %q, got %v", tt.err, err)
+		}
+	}
+
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog:  &plugin.Catalog{DefaultSchema: "public"},
+		Queries: []*plugin.Query{query(&plugin.Column{
+			Name: "ids", Type: &plugin.Identifier{Name: "int8"}, NotNull: true, IsArray: true, ArrayDims: 1,
+		})},
+		PluginOptions: []byte(`{"package": "db", "overrides": [{"db_type": "int8", "go_element_type": "database/sql.NullInt64"}]}`),
+	}
+	files := generateFiles(t, req)
+	if !strings.Contains(files["query.sql.go"], "row.Scan(pq.Array(&ids))") {
+		t.Errorf("query.sql.go does not scan with pq.Array:\n%s", files["query.sql.go"])
+	}
+	testGeneratedCode(t, map[string]string{"db.go": files["db.go"], "query.sql.go": files["query.sql.go"]}, `package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type fakeDriver struct{ args []driver.Value }
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt(c), nil }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type fakeStmt struct{ d *fakeDriver }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.args = args
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{ done bool }
+
+func (*fakeRows) Columns() []string { return []string{"ids"} }
+func (*fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = []byte("{1,NULL,3}")
+	return nil
+}
+
+func TestGetGroup(t *testing.T) {
+	d := &fakeDriver{}
+	sql.Register("fake", d)
+	db, err := sql.Open("fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := New(db).GetGroup(context.Background(), []sql.NullInt64{{Int64: 2, Valid: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sql.NullInt64{{Int64: 1, Valid: true}, {}, {Int64: 3, Valid: true}}
+	if !reflect.DeepEqual(want, ids) {
+		t.Errorf("scanned ids = %v, want %v", ids, want)
+	}
+	if !reflect.DeepEqual([]driver.Value{"{2}"}, d.args) {
+		t.Errorf("bound args = %v, want [{2}]", d.args)
+	}
+}
+`, "github.com/lib/pq v1.10.9")
+}
+
+func TestEnumOverrides(t *testing.T) {
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name: "public",
+				Enums: []*plugin.Enum{
+					{Name: "mood", Vals: []string{"happy", "sad"}},
+					{Name: "status", Vals: []string{"open", "closed"}},
+					{Name: "level", Vals: []string{"low", "high"}},
+				},
+			}},
+		},
+		PluginOptions: []byte(`{"package": "db", "overrides": [
+			{"db_type": "status", "go_type": "github.com/acme/domain.Status"},
+			{"db_type": "level", "go_type": "github.com/acme/domain.Level"},
+			{"db_type": "level", "go_type": "github.com/acme/domain.NullLevel", "nullable": true}
+		]}`),
+	}
+	options, err := opts.Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range buildEnums(req, options) {
+		names = append(names, e.Name)
+	}
+	if diff := cmp.Diff([]string{"Mood"}, names); diff != "" {
+		t.Errorf("enums mismatch;\n%s", diff)
+	}
+	for _, tt := range []struct {
+		enum    string
+		notNull bool
+		want    string
+	}{
+		{"status", true, "domain.Status"},
+		{"status", false, "*domain.Status"},
+		{"level", true, "domain.Level"},
+		{"level", false, "domain.NullLevel"},
+	} {
+		got := goType(req, options, nil, nil, &plugin.Column{Name: tt.enum, Type: &plugin.Identifier{Name: tt.enum}, NotNull: tt.notNull})
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("column type mismatch;\n%s", diff)
+		}
+	}
+}
+
+func TestMySQLSets(t *testing.T) {
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "mysql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name: "public",
+				Enums: []*plugin.Enum{
+					{Name: "users_role", Vals: []string{"admin", "member"}},
+					{Name: "users_perms", Vals: []string{"read", "write"}},
+				},
+			}},
+		},
+		PluginOptions: []byte(`{"package": "db", "mysql_set_columns": ["users.perms"]}`),
+	}
+	options, err := opts.Parse(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sets []string
+	for _, e := range buildEnums(req, options) {
+		sets = append(sets, e.SetName)
+	}
+	if diff := cmp.Diff([]string{"UsersPermsSet", ""}, sets); diff != "" {
+		t.Errorf("set names mismatch;\n%s", diff)
+	}
+	for _, tt := range []struct {
+		col  *plugin.Column
+		want string
+	}{
+		{&plugin.Column{Name: "perms", Type: &plugin.Identifier{Name: "users_perms"}, NotNull: true}, "UsersPermsSet"},
+		{&plugin.Column{Name: "perms", Type: &plugin.Identifier{Name: "users_perms"}}, "*UsersPermsSet"},
+		{&plugin.Column{Name: "role", Type: &plugin.Identifier{Name: "users_role"}, NotNull: true}, "UsersRole"},
+	} {
+		if diff := cmp.Diff(tt.want, goType(req, options, nil, nil, tt.col)); diff != "" {
+			t.Errorf("column type mismatch;\n%s", diff)
+		}
+	}
+}
+
+func TestStrictTypes(t *testing.T) {
+	users := &plugin.Identifier{Schema: "public", Name: "users"}
+	meta := &plugin.Column{Name: "meta", Type: &plugin.Identifier{Name: "hstore"}, NotNull: true, Table: users}
+	req := &plugin.GenerateRequest{
+		Settings: &plugin.Settings{Engine: "postgresql"},
+		Catalog: &plugin.Catalog{
+			DefaultSchema: "public",
+			Schemas: []*plugin.Schema{{
+				Name: "public",
+				Tables: []*plugin.Table{
+					{Rel: users, Columns: []*plugin.Column{meta}},
+				},
+			}},
+		},
+		Queries: []*plugin.Query{{
+			Name:    "CountByTag",
+			Cmd:     metadata.CmdOne,
+			Columns: []*plugin.Column{{Name: "total", Type: &plugin.Identifier{Name: "bigint"}, NotNull: true}},
+			Params: []*plugin.Parameter{
+				{Number: 1, Column: &plugin.Column{Name: "tag", Type: &plugin.Identifier{Name: "cube"}, NotNull: true}},
+			},
+		}},
+	}
+	for _, tt := range []struct {
+		options string
+		err     string
+	}{
+		{
+			`{"package": "db", "strict_types": true}`,
+			"strict_types: no Go type mapping for these columns and parameters, map them with an override or list them in strict_types_allow:\n" +
+				"  column users.meta: db type \"hstore\"\n" +
+				"  param CountByTag.tag: db type \"cube\"",
+		},
+		{
+			`{"package": "db", "strict_types": true, "strict_types_allow": ["users.*"]}`,
+			"strict_types: no Go type mapping for these columns and parameters, map them with an override or list them in strict_types_allow:\n" +
+				"  param CountByTag.tag: db type \"cube\"",
+		},
+		{
+			`{"package": "db", "strict_types": true, "strict_types_allow": ["cube"], "overrides": [{"column": "users.meta", "go_type": {"type": "interface{}"}}]}`,
+			"",
+		},
+		{
+			`{"package": "db", "strict_types": true, "overrides": [
+				{"column": "users.meta", "go_type": {"type": "interface{}"}},
+				{"query": "CountByTag", "param": "tag", "go_type": {"type": "interface{}"}}
+			]}`,
+			"",
+		},
+	} {
+		req.PluginOptions = []byte(tt.options)
+		_, err := Generate(context.Background(), req)
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if diff := cmp.Diff(tt.err, got); diff != "" {
+			t.Errorf("error mismatch;\n%s", diff)
+		}
+	}
+
+	// Optional parameters wrap the unmapped type
+	req.Queries = []*plugin.Query{{
+		Name: "UpdateTag",
+		Cmd:  metadata.CmdExec,
+		Params: []*plugin.Parameter{
+			{Number: 1, Column: &plugin.Column{Name: "set_tag", Type: &plugin.Identifier{Name: "boolean"}, NotNull: true}},
+			{Number: 2, Column: &plugin.Column{Name: "tag", Type: &plugin.Identifier{Name: "cube"}}},
+		},
+	}}
+	req.PluginOptions = []byte(`{"package": "db", "strict_types": true, "strict_types_allow": ["users.*"], "emit_optional_params": true}`)
+	_, err := Generate(context.Background(), req)
+	want := "strict_types: no Go type mapping for these columns and parameters, map them with an override or list them in strict_types_allow:\n" +
+		"  param UpdateTag.tag: db type \"cube\""
+	if err == nil || err.Error() != want {
+		t.Errorf("expected error %q, got %v", want, err)
+	}
+}
+
+func TestPgxNativeTypes(t *testing.T) {
+	columns := []*plugin.Column{
+		{Name: "created_at", Type: &plugin.Identifier{Name: "timestamptz"}},
+		{Name: "id", Type: &plugin.Identifier{Name: "uuid"}, NotNull: true},
+		{Name: "timeout", Type: &plugin.Identifier{Name: "interval"}, NotNull: true},
+		{Name: "network", Type: &plugin.Identifier{Name: "cidr"}},
+		{Name: "note", Type: &plugin.Identifier{Name: "text"}},
+		{Name: "price", Type: &plugin.Identifier{Name: "numeric"}},
+	}
+	for _, tt := range []struct {
+		native bool
+		want   []string
+	}{
+		{false, []string{"pgtype.Timestamptz", "pgtype.UUID", "pgtype.Interval", "*netip.Prefix", "pgtype.Text", "pgtype.Numeric"}},
+		{true, []string{"*time.Time", "uuid.UUID", "pgtype.Interval", "*netip.Prefix", "*string", "pgtype.Numeric"}},
+	} {
+		req := &plugin.GenerateRequest{
+			Settings: &plugin.Settings{Engine: "postgresql"},
+			Catalog:  &plugin.Catalog{DefaultSchema: "public"},
+		}
+		options := &opts.Options{SqlPackage: opts.SQLPackagePGXV5, PgxNativeTypes: tt.native}
+		var got []string
+		for _, col := range columns {
+			got = append(got, goType(req, options, nil, nil, col))
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("pgx_native_types=%v: types mismatch;\n%s", tt.native, diff)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package golang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"github.com/sqlc-dev/plugin-sdk-go/sdk"
+	"github.com/sqlc-dev/sqlc-gen-go/internal/opts"
+)
+
+// checkStrictTypes reports every model column, query parameter and result
+// column whose database type has no Go type mapping, see strict_types.
+func checkStrictTypes(req *plugin.GenerateRequest, options *opts.Options, qo queryOverrideIndex, structs []Struct, queries []Query) error {
+	var unmapped []string
+	seen := make(map[string]struct{})
+	check := func(kind, location, typ string, col *plugin.Column) {
+		if strings.HasPrefix(typ, "Optional[") {
+			typ = strings.TrimSuffix(strings.TrimPrefix(typ, "Optional["), "]")
+		}
+		if col == nil || col.Type == nil || trimSliceAndPointerPrefix(typ) != "interface{}" {
+			return
+		}
+		// An override may map a column to interface{} on purpose, including
+		// one scoped to the parameters and result columns of a query
+		if typeOverride(req, options, qo, col) != nil {
+			return
+		}
+		dbType := sdk.DataType(col.Type)
+		for _, allow := range options.StrictTypesMatch {
+			if allow.MatchString(location) || allow.MatchString(dbType) {
+				return
+			}
+		}
+		line := fmt.Sprintf("%s %s: db type %q", kind, location, dbType)
+		if _, ok := seen[line]; !ok {
+			seen[line] = struct{}{}
+			unmapped = append(unmapped, line)
+		}
+	}
+
+	for _, s := range structs {
+		for _, f := range s.Fields {
+			check("column", s.DBName+"."+f.DBName, f.Type, f.Column)
+		}
+	}
+	for _, q := range queries {
+		if q.Arg.Struct != nil {
+			for _, f := range q.Arg.Struct.Fields {
+				check("param", q.MethodName+"."+f.DBName, f.Type, f.Column)
+			}
+		} else if !q.Arg.isEmpty() {
+			check("param", q.MethodName+"."+q.Arg.DBName, q.Arg.Typ, q.Arg.Column)
+		}
+		if !q.hasRetType() {
+			continue
+		}
+		if q.Ret.Struct != nil {
+			// Models and embedded tables are reported with the table
+			if !q.Ret.EmitStruct() {
+				continue
+			}
+			for _, f := range q.Ret.Struct.Fields {
+				check("result column", q.MethodName+"."+f.DBName, f.Type, f.Column)
+			}
+		} else {
+			check("result column", q.MethodName+"."+q.Ret.DBName, q.Ret.Typ, q.Ret.Column)
+		}
+	}
+
+	if len(unmapped) == 0 {
+		return nil
+	}
+	return fmt.Errorf("strict_types: no Go type mapping for these columns and parameters, map them with an override or list them in strict_types_allow:\n  %s", strings.Join(unmapped, "\n  "))
+}
@@ -12,8 +12,26 @@ import (
 type Struct struct {
 	Table   *plugin.Identifier
 	Name    string
+	DBName  string // Name of the table as used in the DB. Only set for table models.
 	Fields  []Field
 	Comment string
+
+	// Conversions to and from table models, see addModelConversions.
+	ToModels   []ModelConversion
+	FromModels []ModelConversion
+}
+
+// ModelConversion lists the fields copied between a query struct and a
+// table model.
+type ModelConversion struct {
+	Model  string
+	Fields []FieldPair
+}
+
+// FieldPair is a query struct field and the model field of the same column.
+type FieldPair struct {
+	Field      string
+	ModelField string
 }
 
 func StructName(name string, options *opts.Options) string {
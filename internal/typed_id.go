@@ -0,0 +1,141 @@
+package golang
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sqlc-dev/plugin-sdk-go/plugin"
+	"github.com/sqlc-dev/sqlc-gen-go/internal/opts"
+)
+
+// A TypedID is the distinct type generated for the ID column of a table,
+// see the typed_ids option.
+type TypedID struct {
+	Name   string
+	Type   string // underlying Go type
+	DBName string // qualified column, e.g. users.id
+
+	// Integers and strings are scanned through the database/sql null type of
+	// their kind, other types through the Scan and Value methods of Type.
+	NullType  string
+	NullField string
+	ValueType string
+}
+
+// typedIDIndex finds the ID type of columns, see typedIDType. It is built
+// once by buildTypedIDs, before the type of any column is resolved.
+type typedIDIndex struct {
+	config *opts.TypedIDs
+	tables map[string]string // ID types keyed by qualified column, e.g. public.users.id
+	models map[string]string // ID types keyed by the model of their table
+	types  map[string]string // underlying types keyed by ID type
+}
+
+// buildTypedIDs builds the ID types of the tables and the index goType finds
+// them with.
+func buildTypedIDs(req *plugin.GenerateRequest, options *opts.Options) ([]TypedID, *typedIDIndex, error) {
+	if options.TypedIDs == nil {
+		return nil, nil, nil
+	}
+	index := &typedIDIndex{
+		config: options.TypedIDs,
+		tables: map[string]string{},
+		models: map[string]string{},
+		types:  map[string]string{},
+	}
+	var ids []TypedID
+	for _, schema := range req.Catalog.Schemas {
+		if schema.Name == "pg_catalog" || schema.Name == "information_schema" {
+			continue
+		}
+		for _, table := range schema.Tables {
+			for _, column := range table.Columns {
+				if column.IsArray || !index.config.ColumnPattern.MatchString(table.Rel.Name+"."+column.Name) {
+					continue
+				}
+				// An override converting the column has the last word on
+				// how it is scanned.
+				if override := dbTypeOverride(options, column); override != nil && (override.GoEncode != nil || override.GoDecode != nil) {
+					continue
+				}
+				model := modelName(req, options, schema.Name, table.Rel.Name)
+				id := TypedID{
+					Name:   model + StructName(column.Name, options),
+					Type:   goInnerType(req, options, notNullColumn(column)),
+					DBName: table.Rel.Name + "." + column.Name,
+				}
+				if schema.Name != req.Catalog.DefaultSchema {
+					id.DBName = schema.Name + "." + id.DBName
+				}
+				switch id.Type {
+				case "int", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32":
+					id.NullType, id.NullField, id.ValueType = "sql.NullInt64", "Int64", "int64"
+				case "string":
+					id.NullType, id.NullField, id.ValueType = "sql.NullString", "String", "string"
+				default:
+					// Only types of other packages are expected to implement
+					// sql.Scanner and driver.Valuer, and sql.NullInt64 can't
+					// hold every uint64.
+					if _, ok := stdlibTypes[id.Type]; ok || !strings.Contains(id.Type, ".") || strings.ContainsAny(id.Type, "*[") {
+						return nil, nil, fmt.Errorf("typed_ids: ID column %s has type %s, which has no ID type; exclude it from the `columns` pattern", id.DBName, id.Type)
+					}
+				}
+				index.tables[schema.Name+"."+table.Rel.Name+"."+column.Name] = id.Name
+				index.models[model] = id.Name
+				index.types[id.Name] = id.Type
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	if len(ids) > 0 {
+		sort.Slice(ids, func(i, j int) bool { return ids[i].Name < ids[j].Name })
+	}
+	return ids, index, nil
+}
+
+// typedIDType returns the ID type of a column, either the ID column of a
+// table or one referencing it by the foreign_keys naming convention, or an
+// empty string.
+func typedIDType(req *plugin.GenerateRequest, options *opts.Options, index *typedIDIndex, col *plugin.Column) string {
+	if index == nil || col.IsArray {
+		return ""
+	}
+	cname := col.Name
+	if col.OriginalName != "" {
+		cname = col.OriginalName
+	}
+	if col.Table != nil {
+		schema := col.Table.Schema
+		if schema == "" {
+			schema = req.Catalog.DefaultSchema
+		}
+		if name, ok := index.tables[schema+"."+col.Table.Name+"."+cname]; ok {
+			return name
+		}
+	}
+	table, ok := index.config.ForeignKeyTable(cname)
+	if !ok {
+		return ""
+	}
+	name, ok := index.models[modelName(req, options, req.Catalog.DefaultSchema, table)]
+	if !ok {
+		return ""
+	}
+	// A column only references an ID of the same type
+	if goInnerType(req, options, notNullColumn(col)) != index.types[name] {
+		return ""
+	}
+	return name
+}
+
+func notNullColumn(col *plugin.Column) *plugin.Column {
+	return &plugin.Column{
+		Name:     col.Name,
+		NotNull:  true,
+		Unsigned: col.Unsigned,
+		Table:    col.Table,
+		Type:     col.Type,
+	}
+}